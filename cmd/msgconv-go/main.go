@@ -0,0 +1,87 @@
+// Copyright 2013 ChaiShushan <chaishushan{AT}gmail.com>. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// msgconv-go converts a po or mo file to another charset, like GNU's msgconv.
+//
+// The charset of the input is the charset of its header, the header of
+// the output is changed to the new charset. The mo files are detected
+// by the ".mo" extension.
+//
+// Usage:
+//	msgconv-go [-t charset] [-o output] file
+//
+// Examples:
+//	msgconv-go -o ko.po ko.euc-kr.po
+//	msgconv-go -t EUC-KR -o ko.euc-kr.mo ko.mo
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/faxal/gettext-go/gettext/charset"
+	"github.com/faxal/gettext-go/gettext/mo"
+	"github.com/faxal/gettext-go/gettext/po"
+)
+
+var (
+	flagToCode = flag.String("t", "UTF-8", "the charset of the output")
+	flagOutput = flag.String("o", "", "write output to the specified file, default is stdout")
+)
+
+func main() {
+	log.SetFlags(0)
+	log.SetPrefix("msgconv-go: ")
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: msgconv-go [-t charset] [-o output] file\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	if charset.Lookup(*flagToCode) == nil {
+		log.Fatalf("unknown charset %q", *flagToCode)
+	}
+
+	data, err := convert(flag.Arg(0))
+	if err != nil {
+		log.Fatal(err)
+	}
+	if *flagOutput == "" || *flagOutput == "-" {
+		os.Stdout.Write(data)
+		return
+	}
+	if err := ioutil.WriteFile(*flagOutput, data, 0666); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// convert returns the data of the file in the new charset.
+func convert(name string) ([]byte, error) {
+	if filepath.Ext(name) == ".mo" {
+		f, err := mo.Load(name)
+		if err != nil {
+			return nil, err
+		}
+		return f.EncodeData(&mo.EncodeOptions{Charset: *flagToCode}), nil
+	}
+	f, err := po.Load(name)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	enc := po.NewEncoder(&buf)
+	enc.Charset = *flagToCode
+	if err := enc.Encode(f); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
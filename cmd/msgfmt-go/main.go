@@ -0,0 +1,93 @@
+// Copyright 2013 ChaiShushan <chaishushan{AT}gmail.com>. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// msgfmt-go compiles a po file to a mo file, like GNU's msgfmt.
+//
+// The mo file is byte-compatible with msgfmt's. The -c flag enables all the
+// checks: the verbs of the "go-format" messages, the header fields, and the
+// number of plural forms.
+//
+// Usage:
+//	msgfmt-go [-o output.mo] [-f] [-c] [-statistics] file.po
+//
+// Examples:
+//	msgfmt-go -o hello.mo hello.po
+//	msgfmt-go -c -statistics -o /dev/null hello.po
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+
+	"github.com/faxal/gettext-go/gettext/mo"
+	"github.com/faxal/gettext-go/gettext/po"
+)
+
+var (
+	flagOutput      = flag.String("o", "", "write output to the specified file, default is messages.mo")
+	flagUseFuzzy    = flag.Bool("f", false, "use fuzzy entries in output")
+	flagCheck       = flag.Bool("c", false, "perform all the checks")
+	flagCheckFormat = flag.Bool("check-format", false, "check the verbs of the go-format messages")
+	flagCheckHeader = flag.Bool("check-header", false, "check the presence of the header fields")
+	flagCheckPlural = flag.Bool("check-plural", false, "check the number of plural forms is nplurals")
+	flagStatistics  = flag.Bool("statistics", false, "print statistics about translations")
+	flagNoHash      = flag.Bool("no-hash", false, "don't include a hash table")
+)
+
+func main() {
+	log.SetFlags(0)
+	log.SetPrefix("msgfmt-go: ")
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: msgfmt-go [-o output.mo] [-f] [-c] [-statistics] file.po\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	f, err := po.Load(flag.Arg(0))
+	if err != nil {
+		log.Fatal(err)
+	}
+	m, err := f.Compile(&po.CompileOptions{
+		UseFuzzy:    *flagUseFuzzy,
+		CheckFormat: *flagCheck || *flagCheckFormat,
+		CheckHeader: *flagCheck || *flagCheckHeader,
+		CheckPlural: *flagCheck || *flagCheckPlural,
+	})
+	if errs, ok := err.(po.CompileErrors); ok {
+		for _, err := range errs {
+			if err.Line > 0 {
+				fmt.Fprintf(os.Stderr, "%s:%d: %s\n", flag.Arg(0), err.Line, err.Msg)
+			} else {
+				fmt.Fprintf(os.Stderr, "%s: %s\n", flag.Arg(0), err.Msg)
+			}
+		}
+		log.Fatalf("found %d fatal errors", len(errs))
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+	if *flagStatistics {
+		fmt.Fprintf(os.Stderr, "%v\n", f.Statistics())
+	}
+
+	name := *flagOutput
+	if name == "" {
+		name = "messages.mo"
+	}
+	data := m.EncodeData(&mo.EncodeOptions{NoHashTable: *flagNoHash})
+	if name == "-" {
+		os.Stdout.Write(data)
+		return
+	}
+	if err := ioutil.WriteFile(name, data, 0666); err != nil {
+		log.Fatal(err)
+	}
+}
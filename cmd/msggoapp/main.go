@@ -1,9 +1,226 @@
-// Copyright 2013 ChaiShushan <chaishushan{AT}gmail.com>. All rights reserved.
-// Use of this source code is governed by a BSD-style
-// license that can be found in the LICENSE file.
-
-package main
-
-func main() {
-	panic("TODO")
-}
+// Copyright 2013 ChaiShushan <chaishushan{AT}gmail.com>. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// msggoapp updates the message catalogs of a Go application.
+//
+// It extracts the translatable strings of the packages, merges them into
+// the po file of every locale, compiles the mo files, and writes the zip
+// file for gettext.BindTextdomain:
+//
+//	$(root)/$(locale)/LC_MESSAGES/$(domain).po
+//	$(root)/$(locale)/LC_MESSAGES/$(domain).mo
+//
+// The locales are the dirs of root which have a LC_MESSAGES dir, and the
+// new locales of the -locales flag. The po files are merged like GNU's
+// msgmerge, the translated messages which are not used any more become
+// obsolete "#~" entries.
+//
+// The strings of the text/template and html/template files of the -t flag
+// are extracted too, the msgctxt is the template name.
+//
+// With -check, no file is written, msggoapp prints the stale files and
+// exits with status 1 if any catalog is out of date.
+//
+// Usage:
+//	msggoapp [-root dir] [-domain name] [-zip file] [-locales list] [-t pattern]... [-check] [packages...]
+//
+// Examples:
+//	msggoapp -root local -domain hello -zip local.zip ./...
+//	msggoapp -root local -domain hello -locales zh_CN,ja_JP ./...
+//	msggoapp -root local -domain hello -zip local.zip -check ./...
+//	msggoapp -root local -domain hello -t 'templates/*.html' ./...
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/faxal/gettext-go/gettext/po"
+	"github.com/faxal/gettext-go/gettext/xgettext"
+)
+
+var (
+	flagRoot      = flag.String("root", "local", "the root dir of the locales")
+	flagDomain    = flag.String("domain", "messages", "the text domain")
+	flagZip       = flag.String("zip", "", "write the root dir to the zip file")
+	flagLocales   = flag.String("locales", "", "comma separated new locales, like `zh_CN,ja_JP`")
+	flagCheck     = flag.Bool("check", false, "report the stale files and exit 1, don't write any file")
+	flagComment   = flag.String("c", "TRANSLATORS:", "extract the comments start with the tag, empty means none")
+	flagKeywords  keywordsFlag
+	flagTemplates xgettext.TemplatePatterns
+)
+
+func init() {
+	flag.Var(&flagKeywords, "k", "additional keyword spec like `T:1c,2`, can be repeated")
+	flag.Var(&flagTemplates, "t", "template files like `templates/*.html`, can be repeated")
+}
+
+type keywordsFlag []xgettext.Keyword
+
+func (p *keywordsFlag) String() string {
+	return fmt.Sprint(*p)
+}
+
+func (p *keywordsFlag) Set(spec string) error {
+	k, err := xgettext.ParseKeyword(spec)
+	if err != nil {
+		return err
+	}
+	*p = append(*p, k)
+	return nil
+}
+
+func main() {
+	log.SetFlags(0)
+	log.SetPrefix("msggoapp: ")
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: msggoapp [-root dir] [-domain name] [-zip file] [-locales list] [-t pattern]... [-check] [packages...]\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	patterns := flag.Args()
+	if len(patterns) == 0 {
+		patterns = []string{"./..."}
+	}
+
+	pot, err := extract(patterns)
+	if err != nil {
+		log.Fatal(err)
+	}
+	locales, err := findLocales(*flagRoot, *flagLocales)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	var stale []string
+	update := func(name string, data []byte) {
+		old, err := ioutil.ReadFile(name)
+		if err == nil && bytes.Equal(old, data) {
+			return
+		}
+		stale = append(stale, name)
+		if *flagCheck {
+			return
+		}
+		if err := os.MkdirAll(filepath.Dir(name), 0777); err != nil {
+			log.Fatal(err)
+		}
+		if err := ioutil.WriteFile(name, data, 0666); err != nil {
+			log.Fatal(err)
+		}
+	}
+	for _, locale := range locales {
+		dir := filepath.Join(*flagRoot, locale, "LC_MESSAGES")
+		name := filepath.Join(dir, *flagDomain)
+
+		f, err := loadPo(name+".po", locale, pot)
+		if err != nil {
+			log.Fatal(err)
+		}
+		f = po.Merge(f, pot, nil)
+		update(name+".po", f.Data())
+		m, err := f.Compile(nil)
+		if err != nil {
+			log.Fatal(err)
+		}
+		update(name+".mo", m.Data())
+	}
+	if *flagZip != "" && !(*flagCheck && len(stale) != 0) {
+		// in the check mode, the zip is stale if any catalog is stale
+		data, err := zipDir(*flagRoot)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if ok, _ := sameZip(*flagZip, data); !ok {
+			update(*flagZip, data)
+		}
+	}
+
+	if *flagCheck && len(stale) != 0 {
+		for _, name := range stale {
+			fmt.Fprintf(os.Stderr, "%s is out of date\n", name)
+		}
+		os.Exit(1)
+	}
+}
+
+// extract returns the po template file of the packages and templates.
+func extract(patterns []string) (*po.File, error) {
+	x := xgettext.NewExtractor()
+	x.Keywords = append(append([]xgettext.Keyword(nil), xgettext.DefaultKeywords...), flagKeywords...)
+	x.CommentTag = *flagComment
+	paths, err := xgettext.ExpandPackages(patterns)
+	if err != nil {
+		return nil, err
+	}
+	for _, path := range paths {
+		if err := x.ExtractPackage(path); err != nil {
+			return nil, err
+		}
+	}
+	templates, err := xgettext.ExpandTemplates(flagTemplates)
+	if err != nil {
+		return nil, err
+	}
+	if err := x.ExtractTemplateFiles(templates); err != nil {
+		return nil, err
+	}
+	for _, w := range x.Warnings() {
+		log.Printf("warning: %v", w)
+	}
+	return x.File(), nil
+}
+
+// findLocales returns the locales of the root dir, and the new locales.
+func findLocales(root, newLocales string) ([]string, error) {
+	seen := make(map[string]bool)
+	infos, err := ioutil.ReadDir(root)
+	if err != nil && !(os.IsNotExist(err) && newLocales != "") {
+		return nil, err
+	}
+	for _, fi := range infos {
+		if !fi.IsDir() {
+			continue
+		}
+		if _, err := os.Stat(filepath.Join(root, fi.Name(), "LC_MESSAGES")); err == nil {
+			seen[fi.Name()] = true
+		}
+	}
+	for _, s := range strings.Split(newLocales, ",") {
+		if s = strings.TrimSpace(s); s != "" {
+			seen[s] = true
+		}
+	}
+	if len(seen) == 0 {
+		return nil, fmt.Errorf("no locale found in %s", root)
+	}
+	var locales []string
+	for s := range seen {
+		locales = append(locales, s)
+	}
+	sort.Strings(locales)
+	return locales, nil
+}
+
+// loadPo loads the po file, or returns a new po file of the template
+// if it doesn't exist.
+func loadPo(name, locale string, pot *po.File) (*po.File, error) {
+	f, err := po.Load(name)
+	if err == nil || !os.IsNotExist(err) {
+		return f, err
+	}
+	f = &po.File{MimeHeader: pot.MimeHeader}
+	f.MimeHeader.Flags = nil
+	if locale != "default" {
+		f.MimeHeader.Language = locale
+	}
+	return f, nil
+}
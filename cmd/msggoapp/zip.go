@@ -0,0 +1,103 @@
+// Copyright 2013 ChaiShushan <chaishushan{AT}gmail.com>. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+)
+
+// zipDir returns the zip data of the dir, the names of the files
+// start with the base name of dir, like "local/zh_CN/LC_MESSAGES/hello.mo".
+func zipDir(dir string) ([]byte, error) {
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	base := filepath.Base(dir)
+	err := filepath.Walk(dir, func(name string, fi os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, name)
+		if err != nil {
+			return err
+		}
+		zipName := path.Join(base, filepath.ToSlash(rel))
+		if fi.IsDir() {
+			_, err := w.CreateHeader(&zip.FileHeader{Name: zipName + "/", Modified: fi.ModTime()})
+			return err
+		}
+		data, err := ioutil.ReadFile(name)
+		if err != nil {
+			return err
+		}
+		fw, err := w.CreateHeader(&zip.FileHeader{Name: zipName, Method: zip.Deflate, Modified: fi.ModTime()})
+		if err != nil {
+			return err
+		}
+		_, err = fw.Write(data)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// sameZip reports whether the zip file has the same files as the zip data.
+func sameZip(name string, data []byte) (bool, error) {
+	old, err := ioutil.ReadFile(name)
+	if err != nil {
+		return false, err
+	}
+	a, err := zipFiles(old)
+	if err != nil {
+		return false, err
+	}
+	b, err := zipFiles(data)
+	if err != nil {
+		return false, err
+	}
+	if len(a) != len(b) {
+		return false, nil
+	}
+	for k, v := range a {
+		if s, ok := b[k]; !ok || s != v {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// zipFiles returns the contents of the regular files in the zip data.
+func zipFiles(data []byte) (map[string]string, error) {
+	r, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, err
+	}
+	files := make(map[string]string)
+	for _, f := range r.File {
+		if f.FileInfo().IsDir() {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return nil, err
+		}
+		b, err := ioutil.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			return nil, err
+		}
+		files[f.Name] = string(b)
+	}
+	return files, nil
+}
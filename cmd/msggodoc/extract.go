@@ -0,0 +1,124 @@
+// Copyright 2013 ChaiShushan <chaishushan{AT}gmail.com>. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"go/ast"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/faxal/gettext-go/gettext/po"
+)
+
+// extractor extracts the paragraphs of the doc comments.
+type extractor struct {
+	messages []po.Message
+	index    map[string]int // index of messages, by msgctxt and msgid
+}
+
+func newExtractor() *extractor {
+	return &extractor{index: make(map[string]int)}
+}
+
+// extractPackage extracts the package doc, and the docs of the exported
+// symbols in the order of go/doc.
+func (p *extractor) extractPackage(importPath string) error {
+	pkg, err := loadPackage(importPath)
+	if err != nil {
+		return err
+	}
+
+	// the doc comments of the AST, for the references
+	groups := make(map[string][]*ast.CommentGroup)
+	files := make(map[*ast.CommentGroup]string)
+	for i, f := range pkg.files {
+		for _, c := range pkg.fileComments(f) {
+			groups[c.context] = append(groups[c.context], c.group)
+			files[c.group] = path.Join(pkg.doc.ImportPath, filepath.Base(pkg.filenames[i]))
+		}
+	}
+	add := func(recv, name, text string) {
+		context := pkg.symbolContext(recv, name)
+		for _, para := range splitParagraphs(text) {
+			if para.code {
+				continue
+			}
+			file, line := "", 0
+			for _, g := range groups[context] {
+				if i := paragraphIndex(g.Text(), para.text); i >= 0 {
+					file, line = files[g], pkg.fset.Position(g.Pos()).Line+i
+					if strings.HasPrefix(g.List[0].Text, "/*\n") {
+						line++ // the first blank line is removed by Text
+					}
+					break
+				}
+			}
+			p.add(context, para.text, file, line)
+		}
+	}
+
+	add("", "", pkg.doc.Doc)
+	for _, v := range append(pkg.doc.Consts, pkg.doc.Vars...) {
+		add("", valueName(v), v.Doc)
+	}
+	for _, f := range pkg.doc.Funcs {
+		add("", f.Name, f.Doc)
+	}
+	for _, t := range pkg.doc.Types {
+		add("", t.Name, t.Doc)
+		for _, v := range append(t.Consts, t.Vars...) {
+			add("", valueName(v), v.Doc)
+		}
+		for _, f := range t.Funcs {
+			add("", f.Name, f.Doc)
+		}
+		for _, f := range t.Methods {
+			add(t.Name, f.Name, f.Doc)
+		}
+	}
+	return nil
+}
+
+// paragraphIndex returns the line index of the paragraph in the doc text,
+// or -1 if it's not found.
+func paragraphIndex(text, s string) int {
+	for _, para := range splitParagraphs(text) {
+		if para.text == s {
+			return para.line
+		}
+	}
+	return -1
+}
+
+func (p *extractor) add(context, text, file string, line int) {
+	key := context + "\x04" + text
+	if i, ok := p.index[key]; ok {
+		m := &p.messages[i]
+		if file != "" {
+			m.ReferenceFile = append(m.ReferenceFile, file)
+			m.ReferenceLine = append(m.ReferenceLine, line)
+		}
+		return
+	}
+	msg := po.Message{
+		MsgContext: context,
+		MsgId:      text,
+	}
+	if file != "" {
+		msg.ReferenceFile = []string{file}
+		msg.ReferenceLine = []int{line}
+	}
+	p.index[key] = len(p.messages)
+	p.messages = append(p.messages, msg)
+}
+
+// file returns the extracted messages as a po template file.
+func (p *extractor) file() *po.File {
+	return &po.File{
+		MimeHeader: po.NewTemplateHeader(),
+		Messages:   append([]po.Message(nil), p.messages...),
+	}
+}
@@ -1,9 +1,127 @@
-// Copyright 2013 ChaiShushan <chaishushan{AT}gmail.com>. All rights reserved.
-// Use of this source code is governed by a BSD-style
-// license that can be found in the LICENSE file.
-
-package main
-
-func main() {
-	panic("TODO")
-}
+// Copyright 2013 ChaiShushan <chaishushan{AT}gmail.com>. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// msggodoc translates the documentation of Go packages.
+//
+// Without -po, it extracts every paragraph of the doc comments of the
+// package, and the exported consts, vars, funcs, types and methods to a po
+// template file. The msgctxt is the symbol like "pkg.Symbol", the code
+// blocks are not extracted:
+//
+//	github.com/faxal/hello              // package doc
+//	github.com/faxal/hello.Hello        // func, type, const and var
+//	github.com/faxal/hello.Greeter.Say  // method
+//
+// With -po, it renders the documentation translated by the po file as text,
+// html, or Go source files with the translated comments which are written
+// to the -o dir. The untranslated and fuzzy paragraphs are not translated.
+//
+// Usage:
+//	msggodoc [-o output.pot] packages...
+//	msggodoc -po file.po [-format text|html|go] [-o output] package
+//
+// Examples:
+//	msggodoc -o gettext.pot github.com/faxal/gettext-go/gettext
+//	msggodoc -po zh_CN.po github.com/faxal/gettext-go/gettext
+//	msggodoc -po zh_CN.po -format html -o gettext.html github.com/faxal/gettext-go/gettext
+//	msggodoc -po zh_CN.po -format go -o zh_CN/gettext github.com/faxal/gettext-go/gettext
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"time"
+
+	"github.com/faxal/gettext-go/gettext/po"
+	"github.com/faxal/gettext-go/gettext/xgettext"
+)
+
+var (
+	flagOutput = flag.String("o", "", "write output to the specified file or dir, default is stdout")
+	flagPo     = flag.String("po", "", "render the documentation translated by the po file")
+	flagFormat = flag.String("format", "text", "the format of the translated documentation: text, html or go")
+)
+
+func main() {
+	log.SetFlags(0)
+	log.SetPrefix("msggodoc: ")
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: msggodoc [-o output.pot] packages...\n")
+		fmt.Fprintf(os.Stderr, "       msggodoc -po file.po [-format text|html|go] [-o output] package\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() == 0 || (*flagPo != "" && flag.NArg() != 1) {
+		flag.Usage()
+		os.Exit(2)
+	}
+	if *flagPo == "" {
+		extractMain(flag.Args())
+	} else {
+		renderMain(flag.Arg(0))
+	}
+}
+
+func extractMain(patterns []string) {
+	paths, err := xgettext.ExpandPackages(patterns)
+	if err != nil {
+		log.Fatal(err)
+	}
+	x := newExtractor()
+	for _, path := range paths {
+		if err := x.extractPackage(path); err != nil {
+			log.Fatal(err)
+		}
+	}
+	f := x.file()
+	f.MimeHeader.POTCreationDate = time.Now().Format("2006-01-02 15:04-0700")
+	writeOutput(f.Data())
+}
+
+func renderMain(path string) {
+	f, err := po.Load(*flagPo)
+	if err != nil {
+		log.Fatal(err)
+	}
+	pkg, err := loadPackage(path)
+	if err != nil {
+		log.Fatal(err)
+	}
+	tr := newTranslations(f)
+
+	var buf bytes.Buffer
+	switch *flagFormat {
+	case "text":
+		pkg.translate(tr)
+		pkg.writeText(&buf)
+	case "html":
+		pkg.translate(tr)
+		pkg.writeHTML(&buf)
+	case "go":
+		if *flagOutput == "" {
+			log.Fatal("the -o dir is required by the go format")
+		}
+		if err := pkg.writeGoFiles(*flagOutput, tr); err != nil {
+			log.Fatal(err)
+		}
+		return
+	default:
+		log.Fatalf("unknown format %q", *flagFormat)
+	}
+	writeOutput(buf.Bytes())
+}
+
+func writeOutput(data []byte) {
+	if *flagOutput == "" {
+		os.Stdout.Write(data)
+		return
+	}
+	if err := ioutil.WriteFile(*flagOutput, data, 0666); err != nil {
+		log.Fatal(err)
+	}
+}
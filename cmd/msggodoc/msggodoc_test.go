@@ -0,0 +1,174 @@
+// Copyright 2013 ChaiShushan <chaishushan{AT}gmail.com>. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const testHelloGo = `// Package hello says hello.
+//
+// Examples:
+//	hello.Hello()
+package hello
+
+// Hello says hello.
+//
+// It writes to stdout.
+func Hello() {}
+
+// Greeter greets.
+type Greeter struct{}
+
+/*
+Say says hello.
+*/
+func (p *Greeter) Say() {}
+
+// unexported is not extracted.
+func unexported() {}
+`
+
+// testPackage writes the hello module to a temp dir, and changes the
+// working dir to it.
+func testPackage(t *testing.T) {
+	dir := t.TempDir()
+	for name, data := range map[string]string{
+		"go.mod":   "module example.com/hello\n",
+		"hello.go": testHelloGo,
+	} {
+		name = filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(name), 0777); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(name, []byte(data), 0666); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Chdir(dir)
+}
+
+func TestSplitParagraphs(t *testing.T) {
+	tests := []struct {
+		text   string
+		expect []paragraph
+	}{
+		{"", nil},
+		{"a\nb\n", []paragraph{{"a\nb", "", 0, false}}},
+		{"a\n\n\nb\n", []paragraph{{"a", "", 0, false}, {"b", "\n\n", 3, false}}},
+		{"Examples:\n\tx()\n\ty()\nz\n", []paragraph{
+			{"Examples:", "", 0, false},
+			{"\tx()\n\ty()", "\n", 1, true},
+			{"z", "\n", 3, false},
+		}},
+		{"\n a\n\nb", []paragraph{{" a", "", 1, true}, {"b", "\n\n", 3, false}}},
+	}
+	for i, v := range tests {
+		if got := splitParagraphs(v.text); !reflect.DeepEqual(got, v.expect) {
+			t.Fatalf("%d: expect = %v, got = %v", i, v.expect, got)
+		}
+	}
+}
+
+func TestTranslateDoc(t *testing.T) {
+	tr := translations{"ctx\x04a": "A", "ctx\x04\tcode": "CODE"}
+	tests := []struct {
+		text   string
+		expect string
+	}{
+		{"", ""},
+		{"a\n", "A\n"},
+		{"a\n\nb\n", "A\n\nb\n"},
+		{"a\n\tcode\n", "A\n\tcode\n"}, // code is not translated
+	}
+	for i, v := range tests {
+		if got := translateDoc(tr, "ctx", v.text); got != v.expect {
+			t.Fatalf("%d: expect = %q, got = %q", i, v.expect, got)
+		}
+	}
+}
+
+func TestExtractor(t *testing.T) {
+	testPackage(t)
+	x := newExtractor()
+	if err := x.extractPackage("."); err != nil {
+		t.Fatal(err)
+	}
+	type message struct {
+		context, msgid, file string
+		line                 int
+	}
+	expect := []message{
+		{"example.com/hello", "Package hello says hello.", "example.com/hello/hello.go", 1},
+		{"example.com/hello", "Examples:", "example.com/hello/hello.go", 3},
+		{"example.com/hello.Hello", "Hello says hello.", "example.com/hello/hello.go", 7},
+		{"example.com/hello.Hello", "It writes to stdout.", "example.com/hello/hello.go", 9},
+		{"example.com/hello.Greeter", "Greeter greets.", "example.com/hello/hello.go", 12},
+		{"example.com/hello.Greeter.Say", "Say says hello.", "example.com/hello/hello.go", 16},
+	}
+	var got []message
+	for _, msg := range x.file().Messages {
+		if len(msg.ReferenceFile) != 1 {
+			t.Fatalf("%q: bad references: %v", msg.MsgId, msg.ReferenceFile)
+		}
+		got = append(got, message{msg.MsgContext, msg.MsgId, msg.ReferenceFile[0], msg.ReferenceLine[0]})
+	}
+	if !reflect.DeepEqual(got, expect) {
+		t.Fatalf("expect = %v, got = %v", expect, got)
+	}
+}
+
+func TestDocPackage_translate(t *testing.T) {
+	testPackage(t)
+	tr := translations{
+		"example.com/hello\x04Package hello says hello.":   "Package hello 问好.",
+		"example.com/hello.Hello\x04It writes to stdout.":  "写到标准输出.",
+		"example.com/hello.Greeter.Say\x04Say says hello.": "Say 问好.",
+	}
+
+	// the Go files are rendered before translate, which changes the docs
+	pkg, err := loadPackage(".")
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := t.TempDir()
+	if err := pkg.writeGoFiles(dir, tr); err != nil {
+		t.Fatal(err)
+	}
+	data, err := ioutil.ReadFile(filepath.Join(dir, "hello.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := strings.NewReplacer(
+		"// Package hello says hello.", "// Package hello 问好.",
+		"// It writes to stdout.", "// 写到标准输出.",
+		"/*\nSay says hello.\n*/", "// Say 问好.",
+	).Replace(testHelloGo)
+	if string(data) != expect {
+		t.Fatalf("expect = %q, got = %q", expect, data)
+	}
+
+	pkg.translate(tr)
+	var text, html bytes.Buffer
+	pkg.writeText(&text)
+	pkg.writeHTML(&html)
+	for _, s := range []string{"Package hello 问好.", "写到标准输出.", "Say 问好.", "Greeter greets."} {
+		if !strings.Contains(text.String(), s) {
+			t.Fatalf("%q is not in the text: %s", s, text.String())
+		}
+		if !strings.Contains(html.String(), s) {
+			t.Fatalf("%q is not in the html: %s", s, html.String())
+		}
+	}
+	if !strings.Contains(text.String(), "hello.Hello()") {
+		t.Fatalf("the code block is lost: %s", text.String())
+	}
+}
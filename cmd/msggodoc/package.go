@@ -0,0 +1,151 @@
+// Copyright 2013 ChaiShushan <chaishushan{AT}gmail.com>. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"go/ast"
+	"go/doc"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"strings"
+
+	"github.com/faxal/gettext-go/gettext/xgettext"
+)
+
+// docPackage is the parsed documentation of a package.
+type docPackage struct {
+	fset      *token.FileSet
+	filenames []string
+	files     []*ast.File
+	doc       *doc.Package
+}
+
+// loadPackage parses the package of the import path or dir.
+func loadPackage(path string) (*docPackage, error) {
+	bp, err := xgettext.ImportPackage(path)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &docPackage{fset: token.NewFileSet()}
+	for _, name := range append(bp.GoFiles, bp.CgoFiles...) {
+		filename := filepath.Join(bp.Dir, name)
+		f, err := parser.ParseFile(p.fset, filename, nil, parser.ParseComments)
+		if err != nil {
+			return nil, err
+		}
+		p.filenames = append(p.filenames, filename)
+		p.files = append(p.files, f)
+	}
+	// the comments are kept in the AST for the references and the Go files
+	p.doc, err = doc.NewFromFiles(p.fset, p.files, bp.ImportPath, doc.PreserveAST)
+	if err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// symbolContext returns the msgctxt of the symbol, recv is the type
+// name of the method's receiver.
+func (p *docPackage) symbolContext(recv, name string) string {
+	if recv != "" {
+		return p.doc.ImportPath + "." + recv + "." + name
+	}
+	if name != "" {
+		return p.doc.ImportPath + "." + name
+	}
+	return p.doc.ImportPath
+}
+
+// docComment is a doc comment of the package, and its msgctxt.
+type docComment struct {
+	context string
+	group   *ast.CommentGroup
+}
+
+// fileComments returns the doc comments of the exported symbols in the file,
+// like the comments read by go/doc.
+func (p *docPackage) fileComments(f *ast.File) []docComment {
+	var comments []docComment
+	add := func(recv, name string, group *ast.CommentGroup) {
+		if group != nil && ast.IsExported(name) && (recv == "" || ast.IsExported(recv)) {
+			comments = append(comments, docComment{p.symbolContext(recv, name), group})
+		}
+	}
+	if f.Doc != nil {
+		comments = append(comments, docComment{p.symbolContext("", ""), f.Doc})
+	}
+	for _, decl := range f.Decls {
+		switch decl := decl.(type) {
+		case *ast.FuncDecl:
+			add(recvName(decl), decl.Name.Name, decl.Doc)
+		case *ast.GenDecl:
+			for i, spec := range decl.Specs {
+				switch spec := spec.(type) {
+				case *ast.TypeSpec:
+					group := spec.Doc
+					if group == nil && len(decl.Specs) == 1 {
+						group = decl.Doc
+					}
+					add("", spec.Name.Name, group)
+				case *ast.ValueSpec:
+					if i == 0 && decl.Doc != nil {
+						add("", exportedName(decl), decl.Doc)
+					}
+				}
+			}
+		}
+	}
+	return comments
+}
+
+// exportedName returns the first exported name of the value decl,
+// go/doc shows the decl if it has any exported name.
+func exportedName(decl *ast.GenDecl) string {
+	for _, spec := range decl.Specs {
+		for _, name := range spec.(*ast.ValueSpec).Names {
+			if ast.IsExported(name.Name) {
+				return name.Name
+			}
+		}
+	}
+	return ""
+}
+
+// recvName returns the type name of the method's receiver,
+// or "" if it's a function.
+func recvName(decl *ast.FuncDecl) string {
+	if decl.Recv == nil || len(decl.Recv.List) == 0 {
+		return ""
+	}
+	t := decl.Recv.List[0].Type
+	for {
+		switch x := t.(type) {
+		case *ast.StarExpr:
+			t = x.X
+			continue
+		case *ast.IndexExpr:
+			t = x.X
+			continue
+		case *ast.IndexListExpr:
+			t = x.X
+			continue
+		case *ast.Ident:
+			return x.Name
+		}
+		return ""
+	}
+}
+
+// valueName returns the name of the value in the msgctxt.
+func valueName(v *doc.Value) string {
+	for _, name := range v.Names {
+		if ast.IsExported(name) {
+			return name
+		}
+	}
+	return strings.Join(v.Names, ",")
+}
@@ -0,0 +1,67 @@
+// Copyright 2013 ChaiShushan <chaishushan{AT}gmail.com>. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"strings"
+)
+
+// paragraph is a block of the doc text, which is separated by blank lines,
+// or by the indent like the 2013 style "Examples:" followed by the code.
+type paragraph struct {
+	text string // the lines of the block, without the last "\n"
+	sep  string // the separator before the block, "\n\n" or "\n"
+	line int    // the line index in the doc text
+	code bool   // the lines are indented, like a code block
+}
+
+// splitParagraphs splits the doc text of ast.CommentGroup.Text into
+// paragraphs, the multiple blank lines are reduced to one like the Text.
+func splitParagraphs(text string) []paragraph {
+	var (
+		paragraphs []paragraph
+		cur        *paragraph
+		sep        = ""
+	)
+	for i, s := range strings.Split(strings.TrimRight(text, "\n"), "\n") {
+		if strings.TrimSpace(s) == "" {
+			if cur != nil {
+				cur, sep = nil, "\n\n"
+			}
+			continue
+		}
+		code := s[0] == ' ' || s[0] == '\t'
+		if cur != nil && cur.code != code {
+			cur, sep = nil, "\n"
+		}
+		if cur == nil {
+			paragraphs = append(paragraphs, paragraph{text: s, sep: sep, line: i, code: code})
+			cur = &paragraphs[len(paragraphs)-1]
+			continue
+		}
+		cur.text += "\n" + s
+	}
+	return paragraphs
+}
+
+// translateDoc returns the doc text with the translated paragraphs.
+func translateDoc(tr translations, context, text string) string {
+	paragraphs := splitParagraphs(text)
+	if len(paragraphs) == 0 {
+		return text
+	}
+	var buf bytes.Buffer
+	for _, p := range paragraphs {
+		buf.WriteString(p.sep)
+		if s, ok := tr.lookup(context, p.text); ok && !p.code {
+			buf.WriteString(s)
+		} else {
+			buf.WriteString(p.text)
+		}
+	}
+	buf.WriteString("\n")
+	return buf.String()
+}
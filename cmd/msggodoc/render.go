@@ -0,0 +1,276 @@
+// Copyright 2013 ChaiShushan <chaishushan{AT}gmail.com>. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/doc"
+	"go/printer"
+	"html"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/faxal/gettext-go/gettext/po"
+)
+
+// translations are the translated paragraphs of a po file.
+type translations map[string]string
+
+// newTranslations returns the translations of the po file, the obsolete,
+// fuzzy and untranslated messages are skipped.
+func newTranslations(f *po.File) translations {
+	tr := make(translations)
+	for i := 0; i < len(f.Messages); i++ {
+		msg := &f.Messages[i]
+		if msg.Obsolete || msg.GetFuzzy() || msg.MsgStr == "" {
+			continue
+		}
+		tr[msg.MsgContext+"\x04"+msg.MsgId] = msg.MsgStr
+	}
+	return tr
+}
+
+func (tr translations) lookup(context, msgid string) (string, bool) {
+	s, ok := tr[context+"\x04"+msgid]
+	return s, ok
+}
+
+// translate replaces the docs of the package with the translated docs.
+func (p *docPackage) translate(tr translations) {
+	p.doc.Doc = translateDoc(tr, p.symbolContext("", ""), p.doc.Doc)
+	values := func(values []*doc.Value) {
+		for _, v := range values {
+			v.Doc = translateDoc(tr, p.symbolContext("", valueName(v)), v.Doc)
+		}
+	}
+	funcs := func(recv string, funcs []*doc.Func) {
+		for _, f := range funcs {
+			f.Doc = translateDoc(tr, p.symbolContext(recv, f.Name), f.Doc)
+		}
+	}
+	values(p.doc.Consts)
+	values(p.doc.Vars)
+	funcs("", p.doc.Funcs)
+	for _, t := range p.doc.Types {
+		t.Doc = translateDoc(tr, p.symbolContext("", t.Name), t.Doc)
+		values(t.Consts)
+		values(t.Vars)
+		funcs("", t.Funcs)
+		funcs(t.Name, t.Methods)
+	}
+}
+
+// writeText writes the documentation as text, like "go doc -all".
+func (p *docPackage) writeText(w io.Writer) {
+	pr := p.doc.Printer()
+	pr.TextPrefix = "    "
+	pr.TextCodePrefix = "        "
+	text := func(s string) {
+		if s != "" {
+			w.Write(pr.Text(p.doc.Parser().Parse(s)))
+			fmt.Fprintln(w)
+		}
+	}
+
+	fmt.Fprintf(w, "package %s // import %q\n\n", p.doc.Name, p.doc.ImportPath)
+	w.Write(p.doc.Printer().Text(p.doc.Parser().Parse(p.doc.Doc)))
+	fmt.Fprintln(w)
+
+	values := func(title string, values []*doc.Value) {
+		if len(values) == 0 {
+			return
+		}
+		if title != "" {
+			fmt.Fprintf(w, "%s\n\n", title)
+		}
+		for _, v := range values {
+			fmt.Fprintf(w, "%s\n", p.declString(v.Decl))
+			text(v.Doc)
+		}
+	}
+	funcs := func(title string, funcs []*doc.Func) {
+		if len(funcs) == 0 {
+			return
+		}
+		if title != "" {
+			fmt.Fprintf(w, "%s\n\n", title)
+		}
+		for _, f := range funcs {
+			fmt.Fprintf(w, "%s\n", p.declString(f.Decl))
+			text(f.Doc)
+		}
+	}
+	values("CONSTANTS", p.doc.Consts)
+	values("VARIABLES", p.doc.Vars)
+	funcs("FUNCTIONS", p.doc.Funcs)
+	if len(p.doc.Types) != 0 {
+		fmt.Fprintf(w, "TYPES\n\n")
+	}
+	for _, t := range p.doc.Types {
+		fmt.Fprintf(w, "%s\n", p.declString(t.Decl))
+		text(t.Doc)
+		values("", t.Consts)
+		values("", t.Vars)
+		funcs("", t.Funcs)
+		funcs("", t.Methods)
+	}
+}
+
+// writeHTML writes the documentation as a html page.
+func (p *docPackage) writeHTML(w io.Writer) {
+	pr := p.doc.Printer()
+	pr.HeadingLevel = 4
+	docHTML := func(s string) {
+		w.Write(pr.HTML(p.doc.Parser().Parse(s)))
+	}
+	decl := func(id string, node ast.Decl) {
+		fmt.Fprintf(w, "<pre id=\"%s\">%s</pre>\n", html.EscapeString(id), html.EscapeString(p.declString(node)))
+	}
+
+	fmt.Fprintf(w, "<!DOCTYPE html>\n<html>\n<head>\n<meta charset=\"utf-8\">\n")
+	fmt.Fprintf(w, "<title>%s</title>\n</head>\n<body>\n", html.EscapeString(p.doc.Name))
+	fmt.Fprintf(w, "<h1>package %s</h1>\n", html.EscapeString(p.doc.Name))
+	fmt.Fprintf(w, "<p><code>import %q</code></p>\n", html.EscapeString(p.doc.ImportPath))
+	docHTML(p.doc.Doc)
+
+	values := func(values []*doc.Value) {
+		for _, v := range values {
+			decl(valueName(v), v.Decl)
+			docHTML(v.Doc)
+		}
+	}
+	funcs := func(recv string, funcs []*doc.Func) {
+		for _, f := range funcs {
+			id := f.Name
+			if recv != "" {
+				id = recv + "." + f.Name
+			}
+			decl(id, f.Decl)
+			docHTML(f.Doc)
+		}
+	}
+	if len(p.doc.Consts) != 0 {
+		fmt.Fprintf(w, "<h2 id=\"pkg-constants\">Constants</h2>\n")
+		values(p.doc.Consts)
+	}
+	if len(p.doc.Vars) != 0 {
+		fmt.Fprintf(w, "<h2 id=\"pkg-variables\">Variables</h2>\n")
+		values(p.doc.Vars)
+	}
+	if len(p.doc.Funcs) != 0 {
+		fmt.Fprintf(w, "<h2 id=\"pkg-functions\">Functions</h2>\n")
+		funcs("", p.doc.Funcs)
+	}
+	if len(p.doc.Types) != 0 {
+		fmt.Fprintf(w, "<h2 id=\"pkg-types\">Types</h2>\n")
+	}
+	for _, t := range p.doc.Types {
+		fmt.Fprintf(w, "<h3>type %s</h3>\n", html.EscapeString(t.Name))
+		decl(t.Name, t.Decl)
+		docHTML(t.Doc)
+		values(t.Consts)
+		values(t.Vars)
+		funcs("", t.Funcs)
+		funcs(t.Name, t.Methods)
+	}
+	fmt.Fprintf(w, "</body>\n</html>\n")
+}
+
+// declString returns the source code of the decl, without the doc
+// and the func body.
+func (p *docPackage) declString(decl ast.Decl) string {
+	switch d := decl.(type) {
+	case *ast.FuncDecl:
+		x := *d
+		x.Doc, x.Body = nil, nil
+		decl = &x
+	case *ast.GenDecl:
+		x := *d
+		x.Doc = nil
+		decl = &x
+	}
+	var buf bytes.Buffer
+	(&printer.Config{Mode: printer.UseSpaces | printer.TabIndent, Tabwidth: 8}).Fprint(&buf, p.fset, decl)
+	return buf.String()
+}
+
+// writeGoFiles writes the Go files of the package to the dir,
+// with the translated doc comments.
+func (p *docPackage) writeGoFiles(dir string, tr translations) error {
+	if err := os.MkdirAll(dir, 0777); err != nil {
+		return err
+	}
+	for i, f := range p.files {
+		src, err := ioutil.ReadFile(p.filenames[i])
+		if err != nil {
+			return err
+		}
+		data := p.translateSource(src, f, tr)
+		if err := ioutil.WriteFile(filepath.Join(dir, filepath.Base(p.filenames[i])), data, 0666); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// translateSource returns the source code with the translated doc comments,
+// the other code is not changed.
+func (p *docPackage) translateSource(src []byte, f *ast.File, tr translations) []byte {
+	type edit struct {
+		start, end int
+		text       string
+	}
+	var edits []edit
+	for _, c := range p.fileComments(f) {
+		text := c.group.Text()
+		s := translateDoc(tr, c.context, text)
+		if s == text {
+			continue
+		}
+		start := p.fset.Position(c.group.Pos())
+		end := p.fset.Position(c.group.End())
+		indent := string(src[start.Offset-start.Column+1 : start.Offset])
+		edits = append(edits, edit{start.Offset, end.Offset, commentText(s, indent, c.group)})
+	}
+	sort.Slice(edits, func(i, j int) bool { return edits[i].start < edits[j].start })
+
+	var buf bytes.Buffer
+	offset := 0
+	for _, e := range edits {
+		buf.Write(src[offset:e.start])
+		buf.WriteString(e.text)
+		offset = e.end
+	}
+	buf.Write(src[offset:])
+	return buf.Bytes()
+}
+
+// commentText returns the "//" comment of the doc text, the directives
+// of the comment group like "//go:generate" are kept.
+func commentText(text, indent string, group *ast.CommentGroup) string {
+	var lines []string
+	for _, s := range strings.Split(strings.TrimRight(text, "\n"), "\n") {
+		switch {
+		case s == "":
+			lines = append(lines, "//")
+		case s[0] == '\t':
+			lines = append(lines, "//"+s)
+		default:
+			lines = append(lines, "// "+s)
+		}
+	}
+	for _, c := range group.List {
+		if strings.HasPrefix(c.Text, "//go:") || strings.HasPrefix(c.Text, "//line ") {
+			lines = append(lines, c.Text)
+		}
+	}
+	return strings.Join(lines, "\n"+indent)
+}
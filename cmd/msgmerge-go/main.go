@@ -0,0 +1,77 @@
+// Copyright 2013 ChaiShushan <chaishushan{AT}gmail.com>. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// msgmerge-go merges a po file with a new po template file, like GNU's
+// msgmerge.
+//
+// The translations of the changed msgids are taken from the most similar
+// old messages, which are marked fuzzy with the previous msgids. The
+// messages which are not in the template become obsolete.
+//
+// Usage:
+//	msgmerge-go [-o output.po | -U] [-N] def.po ref.pot
+//
+// Examples:
+//	msgmerge-go -o zh_CN.new.po zh_CN.po hello.pot
+//	msgmerge-go -U zh_CN.po hello.pot
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/faxal/gettext-go/gettext/po"
+)
+
+var (
+	flagOutput    = flag.String("o", "", "write output to the specified file, default is stdout")
+	flagUpdate    = flag.Bool("U", false, "update def.po, do nothing if def.po is already up to date")
+	flagNoFuzzy   = flag.Bool("N", false, "do not use fuzzy matching")
+	flagThreshold = flag.Float64("threshold", 0, "minimum similarity of fuzzy matching, 0 means 0.6")
+)
+
+func main() {
+	log.SetFlags(0)
+	log.SetPrefix("msgmerge-go: ")
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: msgmerge-go [-o output.po | -U] [-N] def.po ref.pot\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() != 2 || (*flagUpdate && *flagOutput != "") {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	def, err := po.Load(flag.Arg(0))
+	if err != nil {
+		log.Fatal(err)
+	}
+	ref, err := po.Load(flag.Arg(1))
+	if err != nil {
+		log.Fatal(err)
+	}
+	f := po.Merge(def, ref, &po.MergeOptions{
+		NoFuzzyMatching: *flagNoFuzzy,
+		FuzzyThreshold:  *flagThreshold,
+	})
+
+	switch {
+	case *flagUpdate:
+		if f.String() == def.String() {
+			return
+		}
+		if err := f.Save(flag.Arg(0)); err != nil {
+			log.Fatal(err)
+		}
+	case *flagOutput != "":
+		if err := f.Save(*flagOutput); err != nil {
+			log.Fatal(err)
+		}
+	default:
+		os.Stdout.Write(f.Data())
+	}
+}
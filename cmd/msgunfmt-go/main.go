@@ -0,0 +1,57 @@
+// Copyright 2013 ChaiShushan <chaishushan{AT}gmail.com>. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// msgunfmt-go decompiles a mo file to a po file, like GNU's msgunfmt.
+//
+// The header fields, msgctxt and plural forms are kept. The po file is
+// written to stdout by default.
+//
+// Usage:
+//	msgunfmt-go [-o output.po] file.mo
+//
+// Examples:
+//	msgunfmt-go /usr/share/locale/de/LC_MESSAGES/coreutils.mo
+//	msgunfmt-go -o hello.po hello.mo
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/faxal/gettext-go/gettext/mo"
+	"github.com/faxal/gettext-go/gettext/po"
+)
+
+var (
+	flagOutput = flag.String("o", "", "write output to the specified file, default is stdout")
+)
+
+func main() {
+	log.SetFlags(0)
+	log.SetPrefix("msgunfmt-go: ")
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: msgunfmt-go [-o output.po] file.mo\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	m, err := mo.Load(flag.Arg(0))
+	if err != nil {
+		log.Fatal(err)
+	}
+	f := po.FromMO(m)
+	if *flagOutput == "" || *flagOutput == "-" {
+		os.Stdout.Write(f.Data())
+		return
+	}
+	if err := f.Save(*flagOutput); err != nil {
+		log.Fatal(err)
+	}
+}
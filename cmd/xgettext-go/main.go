@@ -0,0 +1,103 @@
+// Copyright 2013 ChaiShushan <chaishushan{AT}gmail.com>. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+// xgettext-go extracts the translatable strings of Go packages to a po
+// template file, the msgctxt of gettext.Gettext is the caller's name
+// like the gettext package computes at runtime. The strings of the
+// text/template and html/template files of the -t flag are extracted
+// to the same file, the msgctxt is the template name.
+//
+// Usage:
+//	xgettext-go [-o output.pot] [-k keyword]... [-t pattern]... [-c tag] [packages...]
+//
+// Examples:
+//	xgettext-go -o hello.pot ./...
+//	xgettext-go github.com/faxal/gettext-go/examples
+//	xgettext-go -k T:1c,2 -k i18n.N:2,3 ./...
+//	xgettext-go -o hello.pot -t 'templates/*.html' ./...
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"github.com/faxal/gettext-go/gettext/xgettext"
+)
+
+var (
+	flagOutput    = flag.String("o", "", "write output to the specified file, default is stdout")
+	flagComment   = flag.String("c", "TRANSLATORS:", "extract the comments start with the tag, empty means none")
+	flagKeywords  keywordsFlag
+	flagTemplates xgettext.TemplatePatterns
+)
+
+func init() {
+	flag.Var(&flagKeywords, "k", "additional keyword spec like `T:1c,2`, can be repeated")
+	flag.Var(&flagTemplates, "t", "template files like `templates/*.html`, can be repeated")
+}
+
+type keywordsFlag []xgettext.Keyword
+
+func (p *keywordsFlag) String() string {
+	return fmt.Sprint(*p)
+}
+
+func (p *keywordsFlag) Set(spec string) error {
+	k, err := xgettext.ParseKeyword(spec)
+	if err != nil {
+		return err
+	}
+	*p = append(*p, k)
+	return nil
+}
+
+func main() {
+	log.SetFlags(0)
+	log.SetPrefix("xgettext-go: ")
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "usage: xgettext-go [-o output.pot] [-k keyword]... [-t pattern]... [-c tag] [packages...]\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() == 0 && len(flagTemplates) == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	x := xgettext.NewExtractor()
+	x.Keywords = append(append([]xgettext.Keyword(nil), xgettext.DefaultKeywords...), flagKeywords...)
+	x.CommentTag = *flagComment
+	paths, err := xgettext.ExpandPackages(flag.Args())
+	if err != nil {
+		log.Fatal(err)
+	}
+	for _, path := range paths {
+		if err := x.ExtractPackage(path); err != nil {
+			log.Fatal(err)
+		}
+	}
+	templates, err := xgettext.ExpandTemplates(flagTemplates)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if err := x.ExtractTemplateFiles(templates); err != nil {
+		log.Fatal(err)
+	}
+	for _, w := range x.Warnings() {
+		log.Printf("warning: %v", w)
+	}
+	f := x.File()
+	f.MimeHeader.POTCreationDate = time.Now().Format("2006-01-02 15:04-0700")
+
+	if *flagOutput == "" {
+		os.Stdout.Write(f.Data())
+		return
+	}
+	if err := f.Save(*flagOutput); err != nil {
+		log.Fatal(err)
+	}
+}
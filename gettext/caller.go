@@ -7,11 +7,12 @@ package gettext
 import (
 	"regexp"
 	"runtime"
+	"strings"
 )
 
 var (
-	reInit    = regexp.MustCompile(`init·\d+$`) // main.init·1
-	reClosure = regexp.MustCompile(`func·\d+$`) // main.func·001
+	reInit    = regexp.MustCompile(`^init(\.\d+|·\d+)$`)   // init.0, init·1
+	reClosure = regexp.MustCompile(`\.func\d+|^func·\d+$`) // main.func1, func·001
 )
 
 // caller types:
@@ -19,8 +20,10 @@ var (
 // runtime.main
 // main.init
 // main.main
-// main.init·1 -> main.init
-// main.func·001 -> main.func
+// main.init.0 -> main.init
+// main.main.func1 -> main.func
+// main.init.func2.1 -> main.func
+// main.(*T).Method
 // github.com/faxal/gettext-go/gettext.TestCallerName
 // ...
 func callerName(skip int) string {
@@ -28,12 +31,29 @@ func callerName(skip int) string {
 	if !ok {
 		return ""
 	}
-	name := runtime.FuncForPC(pc).Name()
-	if reInit.MatchString(name) {
-		return reInit.ReplaceAllString(name, "init")
+	return funcName(runtime.FuncForPC(pc).Name())
+}
+
+// funcName returns the function name used as the msgctxt, the init
+// functions are named "pkg.init", and the closures are named "pkg.func",
+// the msgctxt don't depend on the Go version and the closure order.
+func funcName(name string) string {
+	// the package path may contain dots, but the last element is escaped
+	pkg, sym := "", name
+	if i := strings.LastIndex(name, "/"); i >= 0 {
+		pkg, sym = name[:i+1], name[i+1:]
 	}
-	if reClosure.MatchString(name) {
-		return reClosure.ReplaceAllString(name, "func")
+	i := strings.Index(sym, ".")
+	if i < 0 {
+		return name
+	}
+	pkg, sym = pkg+sym[:i], sym[i+1:]
+
+	switch {
+	case reInit.MatchString(sym):
+		return pkg + ".init"
+	case reClosure.MatchString(sym):
+		return pkg + ".func"
 	}
 	return name
 }
@@ -87,3 +87,27 @@ func TestCallerName(t *testing.T) {
 		}()
 	}()
 }
+
+func TestFuncName(t *testing.T) {
+	for _, v := range []struct{ name, expect string }{
+		{"main.main", "main.main"},
+		{"main.init", "main.init"},
+		{"main.init.0", "main.init"},
+		{"main.init·1", "main.init"},
+		{"main.func·001", "main.func"},
+		{"main.main.func1", "main.func"},
+		{"main.main.func1.func1", "main.func"},
+		{"main.init.func2.1", "main.func"},
+		{"main.func1", "main.func1"},
+		{"main.(*T).Get", "main.(*T).Get"},
+		{"main.(*T).Get.func1", "main.func"},
+		{"main.G[...]", "main.G[...]"},
+		{"gopkg.in/yaml%2ev2.init.0", "gopkg.in/yaml%2ev2.init"},
+		{"github.com/faxal/gettext-go/gettext.TestCallerName.func3", "github.com/faxal/gettext-go/gettext.func"},
+		{"runtime.goexit", "runtime.goexit"},
+	} {
+		if s := funcName(v.name); s != v.expect {
+			t.Fatalf("%s: expect = %s, got = %s", v.name, v.expect, s)
+		}
+	}
+}
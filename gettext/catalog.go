@@ -0,0 +1,199 @@
+// Copyright 2013 ChaiShushan <chaishushan{AT}gmail.com>. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gettext
+
+import (
+	"io/fs"
+	"time"
+)
+
+// Catalog is a set of bound text domains.
+//
+// A Catalog is bound once and then used through cheap locale-bound
+// Translator views, which are safe for concurrent use by multiple
+// goroutines.
+//
+// Examples:
+//	cat := gettext.NewCatalog()
+//	cat.BindTextdomain("hello", "local", nil)
+//	cat.Textdomain("hello")
+//
+//	de := cat.Locale("de")
+//	fmt.Println(de.PGettext("main.main", "Hello, world!"))
+type Catalog struct {
+	manager *domainManager
+}
+
+// NewCatalog returns a new Catalog with no domains.
+//
+// The current locale of the new Catalog is DefaultLocale.
+func NewCatalog() *Catalog {
+	return &Catalog{
+		manager: newDomainManager(),
+	}
+}
+
+// SetLocale sets and queries the catalog's current locale.
+//
+// It works like the package level SetLocale.
+func (c *Catalog) SetLocale(locale string) string {
+	return c.manager.SetLocale(locale)
+}
+
+// BindTextdomain sets and queries catalog's domains.
+//
+// It works like the package level BindTextdomain.
+func (c *Catalog) BindTextdomain(domain, path string, zipData []byte) (domains, paths []string) {
+	return c.manager.Bind(domain, path, zipData)
+}
+
+// BindTextdomainFS sets and queries catalog's domains.
+//
+// It works like the package level BindTextdomainFS.
+func (c *Catalog) BindTextdomainFS(domain string, fsys fs.FS) (domains, paths []string) {
+	return c.manager.BindFS(domain, fsys)
+}
+
+// SetLocaleFallback sets the fallback chain of the locale.
+//
+// It works like the package level SetLocaleFallback.
+func (c *Catalog) SetLocaleFallback(locale string, fallbacks ...string) {
+	c.manager.SetFallback(locale, fallbacks)
+}
+
+// Textdomain sets and retrieves the catalog's current message domain.
+//
+// It works like the package level Textdomain.
+func (c *Catalog) Textdomain(domain string) string {
+	return c.manager.SetDomain(domain)
+}
+
+// Locales returns the bound locales of the domain, in sorted order.
+//
+// If the domain is empty string, returns the locales of the catalog's
+// current domain. The locales include "default" if it's bound.
+func (c *Catalog) Locales(domain string) []string {
+	return c.manager.Locales(domain)
+}
+
+// SetMissHandler sets the handler of the messages without translation.
+//
+// It works like the package level SetMissHandler.
+func (c *Catalog) SetMissHandler(h MissHandler) {
+	c.manager.SetMissHandler(h)
+}
+
+// Watch reloads the changed translations of the bound domains.
+//
+// It works like the package level Watch.
+func (c *Catalog) Watch(interval time.Duration, fn func(ReloadEvent)) (stop func()) {
+	return newWatcher(c.manager, fn).start(interval)
+}
+
+// Locale returns a Translator bound to the locale.
+//
+// If the locale is empty string, the Translator is bound to the
+// catalog's current locale.
+//
+// Examples:
+//	cat.Locale("zh_CN").Gettext("Hello") // simple chinese
+//	cat.Locale("").Gettext("Hello")      // current locale
+func (c *Catalog) Locale(locale string) *Translator {
+	if locale == "" {
+		locale = c.manager.SetLocale("")
+	}
+	return &Translator{
+		manager: c.manager,
+		locale:  locale,
+	}
+}
+
+// Translator is a locale-bound view of a Catalog.
+//
+// A Translator uses the catalog's current message domain, so it
+// sees every domain bound to the catalog, even the domains bound
+// after the Translator was created.
+type Translator struct {
+	manager *domainManager
+	locale  string
+}
+
+// Locale returns the translator's locale.
+func (t *Translator) Locale() string {
+	return t.locale
+}
+
+// LocaleOf returns the locale in the fallback chain which supplies the
+// translation of the message in the current domain.
+//
+// If no locale has the translation, returns empty string.
+//
+// Examples:
+//	cat.SetLocaleFallback("pt_BR", "pt", "es")
+//	cat.Locale("pt_BR").LocaleOf("main.main", "Hello") // maybe "es"
+func (t *Translator) LocaleOf(msgctxt, msgid string) string {
+	return t.manager.LocaleOf("", t.locale, msgctxt, msgid)
+}
+
+// DLocaleOf like LocaleOf(), but looking up the message in the specified domain.
+func (t *Translator) DLocaleOf(domain, msgctxt, msgid string) string {
+	if domain == "" {
+		return ""
+	}
+	return t.manager.LocaleOf(domain, t.locale, msgctxt, msgid)
+}
+
+// Gettext like the package level Gettext(), but use the translator's locale.
+//
+// It use the caller's function name as the msgctxt.
+func (t *Translator) Gettext(msgid string) string {
+	return t.PGettext(callerName(2), msgid)
+}
+
+// Getdata like the package level Getdata(), but use the translator's locale.
+func (t *Translator) Getdata(name string) []byte {
+	return t.manager.Getdata(t.locale, name)
+}
+
+// NGettext like the package level NGettext(), but use the translator's locale.
+//
+// It use the caller's function name as the msgctxt.
+func (t *Translator) NGettext(msgid, msgidPlural string, n int) string {
+	return t.PNGettext(callerName(2), msgid, msgidPlural, n)
+}
+
+// PGettext like the package level PGettext(), but use the translator's locale.
+func (t *Translator) PGettext(msgctxt, msgid string) string {
+	return t.PNGettext(msgctxt, msgid, "", 0)
+}
+
+// PNGettext like the package level PNGettext(), but use the translator's locale.
+func (t *Translator) PNGettext(msgctxt, msgid, msgidPlural string, n int) string {
+	return t.manager.PNGettext(t.locale, msgctxt, msgid, msgidPlural, n)
+}
+
+// DGettext like the package level DGettext(), but use the translator's locale.
+//
+// It use the caller's function name as the msgctxt.
+func (t *Translator) DGettext(domain, msgid string) string {
+	return t.DPGettext(domain, callerName(2), msgid)
+}
+
+// DNGettext like the package level DNGettext(), but use the translator's locale.
+//
+// It use the caller's function name as the msgctxt.
+func (t *Translator) DNGettext(domain, msgid, msgidPlural string, n int) string {
+	return t.DPNGettext(domain, callerName(2), msgid, msgidPlural, n)
+}
+
+// DPGettext like the package level DPGettext(), but use the translator's locale.
+func (t *Translator) DPGettext(domain, msgctxt, msgid string) string {
+	return t.DPNGettext(domain, msgctxt, msgid, "", 0)
+}
+
+// DPNGettext like the package level DPNGettext(), but use the translator's locale.
+func (t *Translator) DPNGettext(domain, msgctxt, msgid, msgidPlural string, n int) string {
+	return t.manager.DPNGettext(domain, t.locale, msgctxt, msgid, msgidPlural, n)
+}
@@ -0,0 +1,180 @@
+// Copyright 2013 ChaiShushan <chaishushan{AT}gmail.com>. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gettext
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestCatalog(t *testing.T) {
+	cat := NewCatalog()
+	cat.BindTextdomain("hello", "../examples/local", nil)
+	cat.Textdomain("hello")
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j, v := range testTexts {
+				tr := cat.Locale(v.lang)
+				if lang := tr.Locale(); lang != v.lang {
+					t.Errorf("%d: expect = %s, got = %v", j, v.lang, lang)
+					return
+				}
+				if !strings.HasPrefix(v.ctx, "main.") {
+					continue
+				}
+				if dst := tr.PGettext(v.ctx, v.src); dst != v.dst {
+					t.Errorf("%d: expect = %q, got = %q", j, v.dst, dst)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestCatalog_Locale(t *testing.T) {
+	cat := NewCatalog()
+	cat.BindTextdomain("hello", "../examples/local", nil)
+	cat.Textdomain("hello")
+
+	if lang := cat.SetLocale("zh_TW"); lang != "zh_TW" {
+		t.Fatalf("expect = %s, got = %v", "zh_TW", lang)
+	}
+	if lang := cat.Locale("").Locale(); lang != "zh_TW" {
+		t.Fatalf("expect = %s, got = %v", "zh_TW", lang)
+	}
+
+	zhCN, zhTW := cat.Locale("zh_CN"), cat.Locale("zh_TW")
+	if s := zhCN.DPGettext("hello", "main.func", "Gettext in func."); s != "闭包函数中的Gettext." {
+		t.Fatalf("expect = %q, got = %q", "闭包函数中的Gettext.", s)
+	}
+	if s := zhTW.DPGettext("hello", "main.func", "Gettext in func."); s != "閉包函數中的Gettext." {
+		t.Fatalf("expect = %q, got = %q", "閉包函數中的Gettext.", s)
+	}
+	if s := zhTW.DPGettext("unknown", "main.func", "Gettext in func."); s != "Gettext in func." {
+		t.Fatalf("expect = %q, got = %q", "Gettext in func.", s)
+	}
+	if data := zhCN.Getdata("poems.txt"); len(data) == 0 {
+		t.Fatalf("expect non empty poems.txt")
+	}
+}
+
+func TestCatalog_Locales(t *testing.T) {
+	cat := NewCatalog()
+	cat.BindTextdomain("hello", "../examples/local", nil)
+	cat.Textdomain("hello")
+
+	expect := []string{"default", "zh_CN", "zh_TW"}
+	if got := cat.Locales("hello"); !reflect.DeepEqual(got, expect) {
+		t.Fatalf("expect = %v, got = %v", expect, got)
+	}
+	if got := cat.Locales(""); !reflect.DeepEqual(got, expect) {
+		t.Fatalf("expect = %v, got = %v", expect, got)
+	}
+	if got := cat.Locales("unknown"); got != nil {
+		t.Fatalf("expect = nil, got = %v", got)
+	}
+}
+
+func BenchmarkTranslator_Parallel(b *testing.B) {
+	cat := NewCatalog()
+	cat.BindTextdomain("hello", "../examples/local", nil)
+	cat.Textdomain("hello")
+	tr := cat.Locale("zh_CN")
+
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			tr.PGettext(testTexts[0].ctx, testTexts[0].src)
+		}
+	})
+}
+
+// BenchmarkCatalog_PNGettext_Parallel runs the lookups of the bound and
+// the unbound locales concurrently, run it with -race.
+func BenchmarkCatalog_PNGettext_Parallel(b *testing.B) {
+	cat := NewCatalog()
+	cat.BindTextdomain("hello", "../examples/local", nil)
+	cat.Textdomain("hello")
+	cat.SetLocaleFallback("zh_HK", "zh_TW")
+	locales := []*Translator{cat.Locale("zh_CN"), cat.Locale("zh_HK"), cat.Locale("zh_SG")}
+
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for i := 0; pb.Next(); i++ {
+			locales[i%len(locales)].PNGettext("main.func", "Gettext in func.", "", 1)
+		}
+	})
+}
+
+func TestCatalog_LocaleFallback(t *testing.T) {
+	cat := NewCatalog()
+	cat.BindTextdomain("hello", "../examples/local", nil)
+	cat.Textdomain("hello")
+	cat.SetLocaleFallback("zh_HK", "zh_TW")
+
+	for i, v := range testFallbacks {
+		tr := cat.Locale(v.lang)
+		if s := tr.PGettext("main.func", "Gettext in func."); s != v.dst {
+			t.Fatalf("%d: expect = %q, got = %q", i, v.dst, s)
+		}
+		if s := tr.LocaleOf("main.func", "Gettext in func."); s != v.from {
+			t.Fatalf("%d: expect = %q, got = %q", i, v.from, s)
+		}
+		if s := tr.DLocaleOf("hello", "main.func", "Gettext in func."); s != v.from {
+			t.Fatalf("%d: expect = %q, got = %q", i, v.from, s)
+		}
+		if data := tr.Getdata("favicon.ico"); len(data) == 0 {
+			t.Fatalf("%d: expect default favicon.ico", i)
+		}
+	}
+
+	// restore the implicit chain: zh_HK -> zh -> zh_CN
+	cat.SetLocaleFallback("zh_HK")
+	if s := cat.Locale("zh_HK").LocaleOf("main.func", "Gettext in func."); s != "zh_CN" {
+		t.Fatalf("expect = %q, got = %q", "zh_CN", s)
+	}
+}
+
+func TestCatalog_localeChains(t *testing.T) {
+	cat := NewCatalog()
+	cat.BindTextdomain("hello", "../examples/local", nil)
+	cat.Textdomain("hello")
+	n := len(cat.manager.chainMap)
+	if chain := cat.manager.chainMap[cat.manager.makeTrMapKey("hello", "zh")]; len(chain) == 0 || chain[0] != "zh_CN" {
+		t.Fatalf("bad chain of zh: %q", chain)
+	}
+
+	// the locales of the callers are not cached
+	for i := 0; i < 100; i++ {
+		cat.Locale(fmt.Sprintf("zh_X%d", i)).PGettext("main.func", "Gettext in func.")
+	}
+	if got := len(cat.manager.chainMap); got != n {
+		t.Fatalf("expect = %d chains, got = %d", n, got)
+	}
+	if s := cat.Locale("zh_SG").LocaleOf("main.func", "Gettext in func."); s != "zh_CN" {
+		t.Fatalf("expect = %q, got = %q", "zh_CN", s)
+	}
+}
+
+var testFallbacks = []struct {
+	lang string
+	from string
+	dst  string
+}{
+	{"zh_CN", "zh_CN", "闭包函数中的Gettext."},
+	{"zh", "zh_CN", "闭包函数中的Gettext."},
+	{"zh_SG", "zh_CN", "闭包函数中的Gettext."},
+	{"zh_HK", "zh_TW", "閉包函數中的Gettext."},
+	{"zh_TW", "zh_TW", "閉包函數中的Gettext."},
+	{"fr_FR", "", "Gettext in func."},
+}
@@ -0,0 +1,242 @@
+// Copyright 2013 ChaiShushan <chaishushan{AT}gmail.com>. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package charset
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// Encoding is a charset of po and mo files.
+//
+// The ASCII bytes are the same in all the charsets, the other bytes
+// are converted by the table of the charset.
+type Encoding struct {
+	name  string
+	table string // the name of the table file, "" for UTF-8
+}
+
+var encodings = []struct {
+	enc     *Encoding
+	aliases []string
+}{
+	{&Encoding{"UTF-8", ""}, []string{"UTF8", "USASCII", "ASCII", "ANSIX3.41968", "CHARSET"}},
+
+	{&Encoding{"ISO-8859-1", "iso-8859-1"}, []string{"ISO88591", "LATIN1", "L1"}},
+	{&Encoding{"ISO-8859-2", "iso-8859-2"}, []string{"ISO88592", "LATIN2", "L2"}},
+	{&Encoding{"ISO-8859-3", "iso-8859-3"}, []string{"ISO88593", "LATIN3", "L3"}},
+	{&Encoding{"ISO-8859-4", "iso-8859-4"}, []string{"ISO88594", "LATIN4", "L4"}},
+	{&Encoding{"ISO-8859-5", "iso-8859-5"}, []string{"ISO88595", "CYRILLIC"}},
+	{&Encoding{"ISO-8859-6", "iso-8859-6"}, []string{"ISO88596", "ARABIC"}},
+	{&Encoding{"ISO-8859-7", "iso-8859-7"}, []string{"ISO88597", "GREEK"}},
+	{&Encoding{"ISO-8859-8", "iso-8859-8"}, []string{"ISO88598", "HEBREW"}},
+	{&Encoding{"ISO-8859-9", "iso-8859-9"}, []string{"ISO88599", "LATIN5", "L5"}},
+	{&Encoding{"ISO-8859-10", "iso-8859-10"}, []string{"ISO885910", "LATIN6", "L6"}},
+	{&Encoding{"ISO-8859-11", "iso-8859-11"}, []string{"ISO885911", "TIS620"}},
+	{&Encoding{"ISO-8859-13", "iso-8859-13"}, []string{"ISO885913", "LATIN7", "L7"}},
+	{&Encoding{"ISO-8859-14", "iso-8859-14"}, []string{"ISO885914", "LATIN8", "L8"}},
+	{&Encoding{"ISO-8859-15", "iso-8859-15"}, []string{"ISO885915", "LATIN9", "L9"}},
+	{&Encoding{"ISO-8859-16", "iso-8859-16"}, []string{"ISO885916", "LATIN10", "L10"}},
+
+	{&Encoding{"WINDOWS-874", "windows-874"}, []string{"WINDOWS874", "CP874"}},
+	{&Encoding{"WINDOWS-1250", "windows-1250"}, []string{"WINDOWS1250", "CP1250"}},
+	{&Encoding{"WINDOWS-1251", "windows-1251"}, []string{"WINDOWS1251", "CP1251"}},
+	{&Encoding{"WINDOWS-1252", "windows-1252"}, []string{"WINDOWS1252", "CP1252"}},
+	{&Encoding{"WINDOWS-1253", "windows-1253"}, []string{"WINDOWS1253", "CP1253"}},
+	{&Encoding{"WINDOWS-1254", "windows-1254"}, []string{"WINDOWS1254", "CP1254"}},
+	{&Encoding{"WINDOWS-1255", "windows-1255"}, []string{"WINDOWS1255", "CP1255"}},
+	{&Encoding{"WINDOWS-1256", "windows-1256"}, []string{"WINDOWS1256", "CP1256"}},
+	{&Encoding{"WINDOWS-1257", "windows-1257"}, []string{"WINDOWS1257", "CP1257"}},
+	{&Encoding{"WINDOWS-1258", "windows-1258"}, []string{"WINDOWS1258", "CP1258"}},
+	{&Encoding{"KOI8-R", "koi8-r"}, []string{"KOI8R"}},
+	{&Encoding{"KOI8-U", "koi8-u"}, []string{"KOI8U"}},
+
+	// the legacy CJK charsets are decoded by their supersets
+	{&Encoding{"EUC-KR", "cp949"}, []string{"EUCKR", "CP949", "UHC", "KSC5601"}},
+	{&Encoding{"GBK", "gbk"}, []string{"GB2312", "EUCCN", "CP936"}},
+	{&Encoding{"BIG5", "cp950"}, []string{"BIGFIVE", "CNBIG5", "CP950"}},
+	{&Encoding{"SHIFT_JIS", "shift_jis"}, []string{"SHIFTJIS", "SJIS", "MSKANJI"}},
+	{&Encoding{"CP932", "cp932"}, []string{"WINDOWS31J"}},
+}
+
+// UTF8 is the UTF-8 encoding, which doesn't convert anything.
+var UTF8 = encodings[0].enc
+
+// Lookup returns the encoding of the charset name, or nil if the
+// charset is unknown.
+//
+// The names are case insensitive, and "-", "_" and " " are ignored.
+// The US-ASCII and the "CHARSET" of po templates are UTF-8.
+func Lookup(name string) *Encoding {
+	key := normalizeName(name)
+	if key == "" {
+		return UTF8
+	}
+	for _, v := range encodings {
+		if key == normalizeName(v.enc.name) {
+			return v.enc
+		}
+		for _, alias := range v.aliases {
+			if key == alias {
+				return v.enc
+			}
+		}
+	}
+	return nil
+}
+
+func normalizeName(name string) string {
+	return strings.Map(func(r rune) rune {
+		switch r {
+		case '-', '_', ' ':
+			return -1
+		}
+		return r
+	}, strings.ToUpper(strings.TrimSpace(name)))
+}
+
+// Name returns the canonical name of the charset.
+func (e *Encoding) Name() string {
+	return e.name
+}
+
+// IsUTF8 reports whether e is UTF-8, which doesn't need conversion.
+func (e *Encoding) IsUTF8() bool {
+	return e.table == ""
+}
+
+// Decode converts the data from the charset to UTF-8.
+//
+// The invalid bytes are converted to the replacement char U+FFFD.
+func (e *Encoding) Decode(data []byte) []byte {
+	if e.IsUTF8() || isASCII(data) {
+		return data
+	}
+	t := loadTable(e.table)
+	buf := make([]byte, 0, len(data)*3/2)
+	for i := 0; i < len(data); i++ {
+		b := data[i]
+		switch {
+		case b < utf8.RuneSelf:
+			buf = append(buf, b)
+			continue
+		case t.single[b-utf8.RuneSelf] != 0:
+			buf = appendRune(buf, t.single[b-utf8.RuneSelf])
+			continue
+		case t.lead[b] && i+1 < len(data):
+			if r, ok := t.double[uint16(b)<<8|uint16(data[i+1])]; ok {
+				buf = appendRune(buf, r)
+				i++
+				continue
+			}
+		}
+		buf = appendRune(buf, utf8.RuneError)
+	}
+	return buf
+}
+
+// DecodeString is like Decode, but converts a string.
+func (e *Encoding) DecodeString(s string) string {
+	if e.IsUTF8() || isASCII([]byte(s)) {
+		return s
+	}
+	return string(e.Decode([]byte(s)))
+}
+
+// Encode converts the UTF-8 data to the charset.
+//
+// The chars which the charset doesn't have are converted to '?',
+// and the error of the first one is returned.
+func (e *Encoding) Encode(data []byte) ([]byte, error) {
+	if e.IsUTF8() || isASCII(data) {
+		return data, nil
+	}
+	t := loadTable(e.table)
+	var err error
+	buf := make([]byte, 0, len(data))
+	for len(data) > 0 {
+		r, size := utf8.DecodeRune(data)
+		data = data[size:]
+		if r < utf8.RuneSelf {
+			buf = append(buf, byte(r))
+			continue
+		}
+		c, ok := t.codes[r]
+		switch {
+		case !ok:
+			if err == nil {
+				err = fmt.Errorf("gettext: can't convert %q to charset %s", r, e.name)
+			}
+			buf = append(buf, '?')
+		case c > 0xFF:
+			buf = append(buf, byte(c>>8), byte(c))
+		default:
+			buf = append(buf, byte(c))
+		}
+	}
+	return buf, err
+}
+
+// EncodeString is like Encode, but converts a string.
+func (e *Encoding) EncodeString(s string) (string, error) {
+	if e.IsUTF8() || isASCII([]byte(s)) {
+		return s, nil
+	}
+	data, err := e.Encode([]byte(s))
+	return string(data), err
+}
+
+func isASCII(data []byte) bool {
+	for _, b := range data {
+		if b >= utf8.RuneSelf {
+			return false
+		}
+	}
+	return true
+}
+
+func appendRune(buf []byte, r rune) []byte {
+	var tmp [utf8.UTFMax]byte
+	n := utf8.EncodeRune(tmp[:], r)
+	return append(buf, tmp[:n]...)
+}
+
+// ContentTypeCharset returns the charset parameter of the Content-Type
+// header field, or "" if it's missing.
+//
+// Examples:
+//	ContentTypeCharset("text/plain; charset=EUC-KR") // "EUC-KR"
+func ContentTypeCharset(contentType string) string {
+	for _, param := range strings.Split(contentType, ";") {
+		if idx := strings.Index(param, "="); idx >= 0 {
+			if strings.EqualFold(strings.TrimSpace(param[:idx]), "charset") {
+				return strings.Trim(strings.TrimSpace(param[idx+1:]), `"`)
+			}
+		}
+	}
+	return ""
+}
+
+// SetContentTypeCharset returns the Content-Type header field with
+// the charset parameter changed to name.
+//
+// Examples:
+//	SetContentTypeCharset("text/plain; charset=EUC-KR", "UTF-8") // "text/plain; charset=UTF-8"
+func SetContentTypeCharset(contentType, name string) string {
+	params := strings.Split(contentType, ";")
+	for i, param := range params {
+		if idx := strings.Index(param, "="); idx >= 0 {
+			if strings.EqualFold(strings.TrimSpace(param[:idx]), "charset") {
+				params[i] = param[:idx+1] + name
+				return strings.Join(params, ";")
+			}
+		}
+	}
+	if contentType == "" {
+		contentType = "text/plain"
+	}
+	return contentType + "; charset=" + name
+}
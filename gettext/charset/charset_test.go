@@ -0,0 +1,114 @@
+// Copyright 2013 ChaiShushan <chaishushan{AT}gmail.com>. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package charset
+
+import (
+	"testing"
+)
+
+func TestLookup(t *testing.T) {
+	for i, v := range []struct {
+		name   string
+		expect string
+	}{
+		{"", "UTF-8"},
+		{"utf-8", "UTF-8"},
+		{"CHARSET", "UTF-8"},
+		{"US-ASCII", "UTF-8"},
+		{"ISO-8859-1", "ISO-8859-1"},
+		{"iso_8859-15", "ISO-8859-15"},
+		{"latin1", "ISO-8859-1"},
+		{"CP1252", "WINDOWS-1252"},
+		{"euc-kr", "EUC-KR"},
+		{"GB2312", "GBK"},
+		{"big5", "BIG5"},
+		{"Shift_JIS", "SHIFT_JIS"},
+		{"Windows-31J", "CP932"},
+		{"EBCDIC", ""},
+	} {
+		var got string
+		if enc := Lookup(v.name); enc != nil {
+			got = enc.Name()
+		}
+		if got != v.expect {
+			t.Fatalf("%d: expect = %q, got = %q", i, v.expect, got)
+		}
+	}
+}
+
+func TestEncoding(t *testing.T) {
+	for i, v := range []struct {
+		name    string
+		encoded string
+		decoded string
+	}{
+		{"UTF-8", "Käse", "Käse"},
+		{"ISO-8859-1", "K\xe4se", "Käse"},
+		{"ISO-8859-15", "\xa4 5", "€ 5"},
+		{"WINDOWS-1251", "\xcf\xf0\xe8\xe2\xe5\xf2", "Привет"},
+		{"KOI8-R", "\xf0\xd2\xc9\xd7\xc5\xd4", "Привет"},
+		{"EUC-KR", "\xc7\xd1\xb1\xb9\xbe\xee", "한국어"},
+		{"GB2312", "\xc4\xe3\xba\xc3, \xca\xc0\xbd\xe7!", "你好, 世界!"},
+		{"BIG5", "\xa7\x41\xa6\x6e", "你好"},
+		{"SHIFT_JIS", "\x93\xfa\x96\x7b\x8c\xea \xb6\xc0\xb6\xc5", "日本語 ｶﾀｶﾅ"},
+	} {
+		enc := Lookup(v.name)
+		if got := enc.DecodeString(v.encoded); got != v.decoded {
+			t.Fatalf("%d: expect = %q, got = %q", i, v.decoded, got)
+		}
+		if got, err := enc.EncodeString(v.decoded); err != nil || got != v.encoded {
+			t.Fatalf("%d: expect = %q, got = %q, %v", i, v.encoded, got, err)
+		}
+	}
+}
+
+func TestEncoding_invalid(t *testing.T) {
+	enc := Lookup("EUC-KR")
+	if got := enc.DecodeString("a\xc7"); got != "a�" {
+		t.Fatalf("expect = %q, got = %q", "a�", got)
+	}
+	got, err := enc.EncodeString("a☃b")
+	if err == nil || got != "a?b" {
+		t.Fatalf("expect = %q with error, got = %q, %v", "a?b", got, err)
+	}
+}
+
+func TestTables(t *testing.T) {
+	for _, v := range encodings {
+		if v.enc.IsUTF8() {
+			continue
+		}
+		tab := loadTable(v.enc.table)
+		for r, c := range tab.codes {
+			var data []byte
+			if c > 0xFF {
+				data = []byte{byte(c >> 8), byte(c)}
+			} else {
+				data = []byte{byte(c)}
+			}
+			if got := v.enc.DecodeString(string(data)); got != string(r) {
+				t.Fatalf("%s: %X: expect = %q, got = %q", v.enc.name, c, r, got)
+			}
+		}
+	}
+}
+
+func TestContentTypeCharset(t *testing.T) {
+	for i, v := range []struct {
+		s, charset, set string
+	}{
+		{"text/plain; charset=EUC-KR", "EUC-KR", "text/plain; charset=UTF-8"},
+		{"text/plain;charset=\"big5\"", "big5", "text/plain;charset=UTF-8"},
+		{"text/plain", "", "text/plain; charset=UTF-8"},
+		{"", "", "text/plain; charset=UTF-8"},
+	} {
+		if got := ContentTypeCharset(v.s); got != v.charset {
+			t.Fatalf("%d: expect = %q, got = %q", i, v.charset, got)
+		}
+		if got := SetContentTypeCharset(v.s, "UTF-8"); got != v.set {
+			t.Fatalf("%d: expect = %q, got = %q", i, v.set, got)
+		}
+	}
+}
@@ -0,0 +1,25 @@
+// Copyright 2013 ChaiShushan <chaishushan{AT}gmail.com>. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+/*
+Package charset converts the legacy charsets of po and mo files to UTF-8.
+
+The po and mo packages use it to decode the catalogs by the charset of
+the Content-Type header field, and to encode them again when saving.
+
+The supported charsets are the ISO-8859 family, the Windows code pages
+874 and 1250-1258, KOI8-R, KOI8-U, EUC-KR, GBK (and GB2312), Big5 and
+Shift_JIS. The conversion tables are shipped with the package, and are
+loaded at the first use. The legacy CJK charsets are converted by their
+supersets: EUC-KR by CP949, GB2312 by GBK and Big5 by CP950.
+
+Examples:
+	enc := charset.Lookup("EUC-KR")
+	if enc == nil {
+		log.Fatal("unknown charset")
+	}
+	s := enc.DecodeString("\xc7\xd1\xb1\xb9\xbe\xee") // "한국어"
+	data, err := enc.EncodeString(s)
+*/
+package charset
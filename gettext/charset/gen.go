@@ -0,0 +1,118 @@
+// Copyright 2013 ChaiShushan <chaishushan{AT}gmail.com>. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+//go:build ignore
+
+// gen packs the mapping files tables/*.txt to the tables/*.bin files,
+// which are embedded in the charset package.
+//
+// A mapping file has a line for each non-ASCII code, with the code and
+// the Unicode code point in hex, like the mapping files of unicode.org.
+// The third field "dup" marks a code which is only decoded, because the
+// rune is encoded to another code:
+//
+//	0x8790	0x2252	dup
+//
+// The packed table is a list of big-endian uint16 words: the number of
+// the dup codes and the dup codes, and then the number of runs, and every
+// run of the consecutive codes as the first code, the number of codes and
+// their runes.
+//
+// Usage:
+//	go run gen.go
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+func main() {
+	log.SetFlags(0)
+	log.SetPrefix("gen: ")
+	names, err := filepath.Glob(filepath.Join("tables", "*.txt"))
+	if err != nil {
+		log.Fatal(err)
+	}
+	for _, name := range names {
+		data, err := pack(name)
+		if err != nil {
+			log.Fatal(err)
+		}
+		if err := ioutil.WriteFile(strings.TrimSuffix(name, ".txt")+".bin", data, 0666); err != nil {
+			log.Fatal(err)
+		}
+	}
+}
+
+type mapping struct {
+	code uint16
+	r    uint16
+	dup  bool
+}
+
+// pack returns the packed table of the mapping file.
+func pack(name string) ([]byte, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var mappings []mapping
+	s := bufio.NewScanner(f)
+	for line := 1; s.Scan(); line++ {
+		fields := strings.Fields(s.Text())
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		if len(fields) < 2 {
+			return nil, fmt.Errorf("%s:%d: invalid mapping %q", name, line, s.Text())
+		}
+		code, err1 := strconv.ParseUint(fields[0], 0, 16)
+		r, err2 := strconv.ParseUint(fields[1], 0, 16)
+		if err1 != nil || err2 != nil || code < 0x80 {
+			return nil, fmt.Errorf("%s:%d: invalid mapping %q", name, line, s.Text())
+		}
+		if n := len(mappings); n > 0 && uint16(code) <= mappings[n-1].code {
+			return nil, fmt.Errorf("%s:%d: the codes are not sorted", name, line)
+		}
+		dup := len(fields) > 2 && fields[2] == "dup"
+		mappings = append(mappings, mapping{uint16(code), uint16(r), dup})
+	}
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+
+	var runs, dups []uint16
+	var nruns uint16
+	for i := 0; i < len(mappings); {
+		j := i + 1
+		for j < len(mappings) && mappings[j].code == mappings[j-1].code+1 {
+			j++
+		}
+		runs = append(runs, mappings[i].code, uint16(j-i))
+		for _, m := range mappings[i:j] {
+			runs = append(runs, m.r)
+			if m.dup {
+				dups = append(dups, m.code)
+			}
+		}
+		nruns++
+		i = j
+	}
+
+	var buf bytes.Buffer
+	words := append(append(append([]uint16{uint16(len(dups))}, dups...), nruns), runs...)
+	binary.Write(&buf, binary.BigEndian, words)
+	return buf.Bytes(), nil
+}
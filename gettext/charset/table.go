@@ -0,0 +1,88 @@
+// Copyright 2013 ChaiShushan <chaishushan{AT}gmail.com>. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package charset
+
+import (
+	"embed"
+	"encoding/binary"
+	"sync"
+)
+
+// The tables map the non-ASCII codes of the charsets to Unicode, they are
+// packed from the mapping files tables/*.txt by gen.go. The mapping files
+// are in the format of unicode.org, and are not embedded.
+//
+//go:generate go run gen.go
+//go:embed tables/*.bin
+var tableFS embed.FS
+
+// table is the loaded table of a charset.
+type table struct {
+	single [128]rune       // the runes of the single bytes 0x80-0xFF, 0 if invalid
+	lead   [256]bool       // the lead bytes of the double bytes codes
+	double map[uint16]rune // the runes of the double bytes codes
+	codes  map[rune]uint16 // the codes of the runes, for encoding
+}
+
+var (
+	tablesMu sync.Mutex
+	tables   = make(map[string]*table)
+)
+
+// loadTable returns the table of the name, which is loaded
+// at the first use.
+func loadTable(name string) *table {
+	tablesMu.Lock()
+	defer tablesMu.Unlock()
+	if t, ok := tables[name]; ok {
+		return t
+	}
+	data, err := tableFS.ReadFile("tables/" + name + ".bin")
+	if err != nil {
+		panic("gettext: missing charset table " + name)
+	}
+	t := parseTable(data)
+	tables[name] = t
+	return t
+}
+
+// parseTable parses the packed table of gen.go.
+func parseTable(data []byte) *table {
+	t := &table{
+		double: make(map[uint16]rune),
+		codes:  make(map[rune]uint16),
+	}
+	word := func() uint16 {
+		if len(data) < 2 {
+			panic("gettext: invalid charset table")
+		}
+		v := binary.BigEndian.Uint16(data)
+		data = data[2:]
+		return v
+	}
+
+	// the dup codes are only decoded, the runes are encoded to the
+	// other codes
+	dups := make(map[uint16]bool)
+	for n := word(); n > 0; n-- {
+		dups[word()] = true
+	}
+	for runs := word(); runs > 0; runs-- {
+		code, n := word(), word()
+		for ; n > 0; code, n = code+1, n-1 {
+			r := rune(word())
+			if code > 0xFF {
+				t.lead[code>>8] = true
+				t.double[code] = r
+			} else {
+				t.single[code-0x80] = r
+			}
+			if !dups[code] {
+				t.codes[r] = code
+			}
+		}
+	}
+	return t
+}
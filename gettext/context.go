@@ -0,0 +1,88 @@
+// Copyright 2013 ChaiShushan <chaishushan{AT}gmail.com>. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gettext
+
+import (
+	"context"
+)
+
+type translatorKey struct{}
+
+// NewContext returns a new Context that carries the Translator.
+//
+// Examples:
+//	ctx = gettext.NewContext(ctx, cat.Locale("zh_CN"))
+//	msg := gettext.CtxGettext(ctx, "Hello") // translated to simple chinese
+func NewContext(ctx context.Context, tr *Translator) context.Context {
+	return context.WithValue(ctx, translatorKey{}, tr)
+}
+
+// FromContext returns the Translator carried by the context, if any.
+func FromContext(ctx context.Context) (tr *Translator, ok bool) {
+	tr, ok = ctx.Value(translatorKey{}).(*Translator)
+	return tr, ok && tr != nil
+}
+
+// ctxTranslator returns the Translator carried by the context, or the
+// Translator of the package level functions.
+func ctxTranslator(ctx context.Context) *Translator {
+	if tr, ok := FromContext(ctx); ok {
+		return tr
+	}
+	return defaultCatalog.Locale("")
+}
+
+// CtxGettext like Gettext(), but use the Translator carried by the context.
+//
+// It use the caller's function name as the msgctxt.
+func CtxGettext(ctx context.Context, msgid string) string {
+	return ctxTranslator(ctx).PGettext(callerName(2), msgid)
+}
+
+// CtxGetdata like Getdata(), but use the Translator carried by the context.
+func CtxGetdata(ctx context.Context, name string) []byte {
+	return ctxTranslator(ctx).Getdata(name)
+}
+
+// CtxNGettext like NGettext(), but use the Translator carried by the context.
+//
+// It use the caller's function name as the msgctxt.
+func CtxNGettext(ctx context.Context, msgid, msgidPlural string, n int) string {
+	return ctxTranslator(ctx).PNGettext(callerName(2), msgid, msgidPlural, n)
+}
+
+// CtxPGettext like PGettext(), but use the Translator carried by the context.
+func CtxPGettext(ctx context.Context, msgctxt, msgid string) string {
+	return ctxTranslator(ctx).PNGettext(msgctxt, msgid, "", 0)
+}
+
+// CtxPNGettext like PNGettext(), but use the Translator carried by the context.
+func CtxPNGettext(ctx context.Context, msgctxt, msgid, msgidPlural string, n int) string {
+	return ctxTranslator(ctx).PNGettext(msgctxt, msgid, msgidPlural, n)
+}
+
+// CtxDGettext like DGettext(), but use the Translator carried by the context.
+//
+// It use the caller's function name as the msgctxt.
+func CtxDGettext(ctx context.Context, domain, msgid string) string {
+	return ctxTranslator(ctx).DPNGettext(domain, callerName(2), msgid, "", 0)
+}
+
+// CtxDNGettext like DNGettext(), but use the Translator carried by the context.
+//
+// It use the caller's function name as the msgctxt.
+func CtxDNGettext(ctx context.Context, domain, msgid, msgidPlural string, n int) string {
+	return ctxTranslator(ctx).DPNGettext(domain, callerName(2), msgid, msgidPlural, n)
+}
+
+// CtxDPGettext like DPGettext(), but use the Translator carried by the context.
+func CtxDPGettext(ctx context.Context, domain, msgctxt, msgid string) string {
+	return ctxTranslator(ctx).DPNGettext(domain, msgctxt, msgid, "", 0)
+}
+
+// CtxDPNGettext like DPNGettext(), but use the Translator carried by the context.
+func CtxDPNGettext(ctx context.Context, domain, msgctxt, msgid, msgidPlural string, n int) string {
+	return ctxTranslator(ctx).DPNGettext(domain, msgctxt, msgid, msgidPlural, n)
+}
@@ -0,0 +1,70 @@
+// Copyright 2013 ChaiShushan <chaishushan{AT}gmail.com>. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gettext
+
+import (
+	"context"
+	"testing"
+	"testing/fstest"
+)
+
+const testContextPoData = `msgid ""
+msgstr ""
+"Content-Type: text/plain; charset=UTF-8\n"
+"Plural-Forms: nplurals=2; plural=(n != 1);\n"
+
+msgctxt "github.com/faxal/gettext-go/gettext.TestContext"
+msgid "Hello"
+msgstr "Hallo"
+
+msgctxt "github.com/faxal/gettext-go/gettext.TestContext"
+msgid "%d file"
+msgid_plural "%d files"
+msgstr[0] "%d Datei"
+msgstr[1] "%d Dateien"
+
+msgctxt "github.com/faxal/gettext-go/gettext.func"
+msgid "Hello"
+msgstr "Hallo aus der Closure"
+
+msgctxt "ctx"
+msgid "Hello"
+msgstr "Hallo mit Kontext"
+`
+
+func TestContext(t *testing.T) {
+	cat := NewCatalog()
+	cat.BindTextdomainFS("test", fstest.MapFS{
+		"de/LC_MESSAGES/test.po": &fstest.MapFile{Data: []byte(testContextPoData)},
+	})
+	cat.Textdomain("test")
+	ctx := NewContext(context.Background(), cat.Locale("de"))
+
+	if tr, ok := FromContext(ctx); !ok || tr.Locale() != "de" {
+		t.Fatalf("bad translator: %v, %v", tr, ok)
+	}
+	if _, ok := FromContext(context.Background()); ok {
+		t.Fatalf("expect no translator")
+	}
+
+	for i, v := range []struct {
+		got, expect string
+	}{
+		{CtxGettext(ctx, "Hello"), "Hallo"},
+		{CtxNGettext(ctx, "%d file", "%d files", 2), "%d Dateien"},
+		{CtxPGettext(ctx, "ctx", "Hello"), "Hallo mit Kontext"},
+		{CtxPNGettext(ctx, "ctx", "Hello", "", 0), "Hallo mit Kontext"},
+		{CtxDGettext(ctx, "test", "Hello"), "Hallo"},
+		{CtxDNGettext(ctx, "test", "%d file", "%d files", 1), "%d Datei"},
+		{CtxDPGettext(ctx, "test", "ctx", "Hello"), "Hallo mit Kontext"},
+		{CtxDPNGettext(ctx, "test", "ctx", "Hello", "", 0), "Hallo mit Kontext"},
+		{func() string { return CtxGettext(ctx, "Hello") }(), "Hallo aus der Closure"},
+		{CtxGettext(context.Background(), "Hello"), "Hello"},
+	} {
+		if v.got != v.expect {
+			t.Fatalf("%d: expect = %q, got = %q", i, v.expect, v.got)
+		}
+	}
+}
@@ -17,6 +17,7 @@ Example:
 		// gettext.BindTextdomain("hello", "local", nil)         // from local dir
 		// gettext.BindTextdomain("hello", "local.zip", nil)     // from local zip file
 		// gettext.BindTextdomain("hello", "local.zip", zipData) // from embedded zip data
+		// gettext.BindTextdomainFS("hello", localFS)           // from embed.FS or any fs.FS
 
 		gettext.BindTextdomain("hello", "local", nil)
 
@@ -29,9 +30,67 @@ Example:
 		// Output: ...
 	}
 
+Translate for many locales at the same time(e.g. in HTTP servers):
+
+	cat := gettext.NewCatalog()
+	cat.BindTextdomain("hello", "local", nil)
+	cat.Textdomain("hello")
+
+	// locale-bound views, safe for concurrent use
+	zhCN := cat.Locale("zh_CN")
+	zhTW := cat.Locale("zh_TW")
+
+	fmt.Println(zhCN.Gettext("Hello, world!"))
+	// Output: 你好, 世界!
+	fmt.Println(zhTW.Gettext("Hello, world!"))
+	// Output: 你好, 世界!
+
+	// the package level functions use a default Catalog
+
+Web servers use the httpi18n package to choose the Translator of each
+request by the Accept-Language header, instead of SetLocale:
+
+	http.Handle("/", httpi18n.Handler(cat, "hello", nil, handler))
+
+The Translator is carried by the request context, and the Ctx functions
+translate with it, or with the default Catalog if the context has none:
+
+	ctx = gettext.NewContext(ctx, cat.Locale("zh_CN"))
+	fmt.Println(gettext.CtxGettext(ctx, "Hello, world!"))
+
+The tmpli18n package has the translate functions of the text/template
+and html/template templates, whose msgctxt is the template name:
+
+	{{gettext . "Hello, world!"}}
+
+The msgctxt of Gettext, NGettext, DGettext and DNGettext is the caller's
+function name, like "main.main", "main.init" (also for the init of package
+variables), "github.com/faxal/hello.(*T).Get", and "main.func" for all the
+closures. Use the xgettext-go command to extract the messages with the same
+msgctxt to a po template file:
+
+	xgettext-go -o hello.pot ./...
+
+The msggoapp command updates the po and mo files of all the locales, and
+the zip file for BindTextdomain:
+
+	msggoapp -root local -domain hello -zip local.zip ./...
+
+SetMissHandler reports the messages without translation, the built-in
+MissCollector writes them as a po template file for translators:
+
+	c := gettext.NewMissCollector()
+	gettext.SetMissHandler(c.Handle)
+
+Watch polls the bound dirs and zip files, and reloads the changed mo
+and po files without restarting the program:
+
+	stop := gettext.Watch(2*time.Second, nil)
+	defer stop()
+
 Translate directory struct("../examples/local.zip"):
 
-	Root: "path" or "file.zip/zipBaseName"
+	Root: "path" or "file.zip/zipBaseName" or "fs.FS/subDir"
 	 +-default                 # local: $(LC_MESSAGES) or $(LANG) or "default"
 	 |  +-LC_MESSAGES            # just for `gettext.Gettext`
 	 |  |   +-hello.mo             # $(Root)/$(local)/LC_MESSAGES/$(domain).mo
@@ -4,21 +4,32 @@
 
 package gettext
 
-import "sync"
+import (
+	"fmt"
+	"io/fs"
+	"sort"
+	"strings"
+	"sync"
+)
 
 type domainManager struct {
-	mutex     sync.Mutex
-	locale    string
-	domain    string
-	domainMap map[string]*fileSystem
-	trTextMap map[string]*translator
+	mutex       sync.RWMutex
+	locale      string
+	domain      string
+	domainMap   map[string]*fileSystem
+	trTextMap   map[string]*translator
+	fallbackMap map[string][]string
+	missFilter  *missFilter
+	chainMap    map[string][]string // the locale chains of the known locales
 }
 
 func newDomainManager() *domainManager {
 	return &domainManager{
-		locale:    DefaultLocale,
-		domainMap: make(map[string]*fileSystem),
-		trTextMap: make(map[string]*translator),
+		locale:      DefaultLocale,
+		domainMap:   make(map[string]*fileSystem),
+		trTextMap:   make(map[string]*translator),
+		fallbackMap: make(map[string][]string),
+		chainMap:    make(map[string][]string),
 	}
 }
 
@@ -27,14 +38,32 @@ func (p *domainManager) makeTrMapKey(domain, locale string) string {
 }
 
 func (p *domainManager) Bind(domain, path string, data []byte) (domains, paths []string) {
+	var fs *fileSystem
+	if domain != "" && path != "" {
+		fs = newFileSystem(path, data)
+	}
+	return p.bindFileSystem(domain, fs)
+}
+
+func (p *domainManager) BindFS(domain string, fsys fs.FS) (domains, paths []string) {
+	var fs *fileSystem
+	if domain != "" && fsys != nil {
+		fs = newFileSystemFS(fmt.Sprintf("%T", fsys), fsys)
+	}
+	return p.bindFileSystem(domain, fs)
+}
+
+func (p *domainManager) bindFileSystem(domain string, fs *fileSystem) (domains, paths []string) {
 	p.mutex.Lock()
 	defer p.mutex.Unlock()
 
 	switch {
-	case domain != "" && path != "": // bind new domain
-		p.bindDomainTranslators(domain, path, data)
-	case domain != "" && path == "": // delete domain
+	case domain != "" && fs != nil: // bind new domain
+		p.bindDomainTranslators(domain, fs)
+		p.resetLocaleChains()
+	case domain != "" && fs == nil: // delete domain
 		p.deleteDomain(domain)
+		p.resetLocaleChains()
 	}
 
 	// return all bind domain
@@ -48,12 +77,24 @@ func (p *domainManager) Bind(domain, path string, data []byte) (domains, paths [
 func (p *domainManager) SetLocale(locale string) string {
 	p.mutex.Lock()
 	defer p.mutex.Unlock()
-	if locale != "" {
+	if locale != "" && locale != p.locale {
 		p.locale = locale
+		p.resetLocaleChains()
 	}
 	return p.locale
 }
 
+func (p *domainManager) SetFallback(locale string, fallbacks []string) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if len(fallbacks) != 0 {
+		p.fallbackMap[locale] = append([]string(nil), fallbacks...)
+	} else {
+		delete(p.fallbackMap, locale)
+	}
+	p.resetLocaleChains()
+}
+
 func (p *domainManager) SetDomain(domain string) string {
 	p.mutex.Lock()
 	defer p.mutex.Unlock()
@@ -63,51 +104,208 @@ func (p *domainManager) SetDomain(domain string) string {
 	return p.domain
 }
 
-func (p *domainManager) Getdata(name string) []byte {
-	return p.getdata(p.domain, name)
+func (p *domainManager) Locales(domain string) []string {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+	if domain == "" {
+		domain = p.domain
+	}
+	fs, ok := p.domainMap[domain]
+	if !ok {
+		return nil
+	}
+	var locales []string
+	for k := range fs.LocaleMap {
+		locales = append(locales, k)
+	}
+	sort.Strings(locales)
+	return locales
 }
 
-func (p *domainManager) PNGettext(msgctxt, msgid, msgidPlural string, n int) string {
-	p.mutex.Lock()
-	defer p.mutex.Unlock()
-	return p.gettext(p.domain, msgctxt, msgid, msgidPlural, n)
+func (p *domainManager) Getdata(locale, name string) []byte {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+	return p.getdata(p.domain, locale, name)
+}
+
+func (p *domainManager) PNGettext(locale, msgctxt, msgid, msgidPlural string, n int) string {
+	p.mutex.RLock()
+	domain := p.domain
+	p.mutex.RUnlock()
+	return p.DPNGettext(domain, locale, msgctxt, msgid, msgidPlural, n)
+}
+
+func (p *domainManager) DPNGettext(domain, locale, msgctxt, msgid, msgidPlural string, n int) string {
+	p.mutex.RLock()
+	s, ok := p.gettext(domain, locale, msgctxt, msgid, msgidPlural, n)
+	miss := p.missFilter
+	p.mutex.RUnlock()
+
+	// the handler is called without the lock, it may translate too
+	if !ok && miss != nil && domain != "" && locale != "" {
+		miss.report(domain, locale, msgctxt, msgid, msgidPlural)
+	}
+	return s
 }
 
-func (p *domainManager) DPNGettext(domain, msgctxt, msgid, msgidPlural string, n int) string {
+func (p *domainManager) SetMissHandler(h MissHandler) {
 	p.mutex.Lock()
 	defer p.mutex.Unlock()
-	return p.gettext(domain, msgctxt, msgid, msgidPlural, n)
+	if h != nil {
+		p.missFilter = newMissFilter(h)
+	} else {
+		p.missFilter = nil
+	}
 }
 
-func (p *domainManager) gettext(domain, msgctxt, msgid, msgidPlural string, n int) string {
-	if p.locale == "" || p.domain == "" {
-		return msgid
+func (p *domainManager) LocaleOf(domain, locale, msgctxt, msgid string) string {
+	p.mutex.RLock()
+	defer p.mutex.RUnlock()
+	if domain == "" {
+		domain = p.domain
 	}
-	if _, ok := p.domainMap[domain]; !ok {
-		return msgid
+	if locale == "" || domain == "" {
+		return ""
 	}
-	if f, ok := p.trTextMap[p.makeTrMapKey(domain, p.locale)]; ok {
-		return f.PNGettext(msgctxt, msgid, msgidPlural, n)
+	for _, v := range p.localeChain(domain, locale) {
+		if f, ok := p.trTextMap[p.makeTrMapKey(domain, v)]; ok {
+			if len(f.findMsgStrPlural(msgctxt, msgid, "")) != 0 {
+				return v
+			}
+		}
 	}
-	return msgid
+	return ""
 }
 
-func (p *domainManager) getdata(domain, name string) []byte {
-	if p.locale == "" || p.domain == "" {
-		return nil
+// gettext returns the translation, or the msgid and false if the message
+// has no translation.
+func (p *domainManager) gettext(domain, locale, msgctxt, msgid, msgidPlural string, n int) (string, bool) {
+	if locale != "" && domain != "" {
+		for _, v := range p.localeChain(domain, locale) {
+			if f, ok := p.trTextMap[p.makeTrMapKey(domain, v)]; ok {
+				if s, ok := f.lookup(msgctxt, msgid, msgidPlural, n); ok {
+					return s, true
+				}
+			}
+		}
+	}
+	if msgidPlural != "" && n != 1 {
+		return msgidPlural, false
 	}
-	if _, ok := p.domainMap[domain]; !ok {
+	return msgid, false
+}
+
+func (p *domainManager) getdata(domain, locale, name string) []byte {
+	if locale == "" || domain == "" {
 		return nil
 	}
 	if fs, ok := p.domainMap[domain]; ok {
-		if data, err := fs.LoadResourceFile(domain, p.locale, name); err == nil {
-			return data
-		}
-		if p.locale != "default" {
-			if data, err := fs.LoadResourceFile(domain, "default", name); err == nil {
+		for _, v := range p.localeChain(domain, locale) {
+			if data, err := fs.LoadResourceFile(domain, v, name); err == nil {
 				return data
 			}
 		}
 	}
 	return nil
 }
+
+// localeChain returns the bound locales of the domain to try in order,
+// it must be called with p.mutex locked for reading.
+//
+// The chains of the known locales are built by resetLocaleChains, the
+// chains of the other locales given by the callers are not cached.
+func (p *domainManager) localeChain(domain, locale string) []string {
+	if chain, ok := p.chainMap[p.makeTrMapKey(domain, locale)]; ok {
+		return chain
+	}
+	return p.makeLocaleChain(domain, locale)
+}
+
+// makeLocaleChain returns the locale chain of the domain.
+//
+// The chain is the locale, its explicit fallbacks or its language,
+// and "default" at last:
+//	de_AT -> de -> default
+//	pt_BR -> pt -> es -> default // SetFallback("pt_BR", "pt", "es")
+//
+// A language without territory also matches the bound locales of
+// that language, so "zh" finds "zh_CN".
+func (p *domainManager) makeLocaleChain(domain, locale string) []string {
+	fs, ok := p.domainMap[domain]
+	if !ok {
+		return nil
+	}
+
+	names := []string{locale}
+	if fallbacks, ok := p.fallbackMap[locale]; ok {
+		names = append(names, fallbacks...)
+	} else if lang := localeLanguage(locale); lang != locale {
+		names = append(names, lang)
+	}
+	names = append(names, "default")
+
+	var chain []string
+	var seen = make(map[string]bool)
+	var add = func(s string) {
+		if !seen[s] {
+			seen[s] = true
+			chain = append(chain, s)
+		}
+	}
+	for _, name := range names {
+		if fs.LocaleMap[name] {
+			add(name)
+			continue
+		}
+		if localeLanguage(name) != name {
+			continue
+		}
+		var matched []string
+		for s := range fs.LocaleMap {
+			if localeLanguage(s) == name {
+				matched = append(matched, s)
+			}
+		}
+		sort.Strings(matched)
+		for _, s := range matched {
+			add(s)
+		}
+	}
+	return chain
+}
+
+// resetLocaleChains builds the locale chains of the known locales: the
+// current locale, the locales with fallbacks, and the bound locales and
+// their languages of every domain.
+//
+// must called with p.mutex locked
+func (p *domainManager) resetLocaleChains() {
+	p.chainMap = make(map[string][]string)
+	for domain, fs := range p.domainMap {
+		locales := []string{p.locale}
+		for locale := range p.fallbackMap {
+			locales = append(locales, locale)
+		}
+		for locale := range fs.LocaleMap {
+			locales = append(locales, locale, localeLanguage(locale))
+		}
+		for _, locale := range locales {
+			key := p.makeTrMapKey(domain, locale)
+			if _, ok := p.chainMap[key]; !ok {
+				p.chainMap[key] = p.makeLocaleChain(domain, locale)
+			}
+		}
+	}
+}
+
+// localeLanguage returns the language part of the locale:
+//	de_AT       -> de
+//	zh-Hant     -> zh
+//	sr@latin    -> sr
+//	en_US.UTF-8 -> en
+func localeLanguage(locale string) string {
+	if idx := strings.IndexAny(locale, "_-.@"); idx > 0 {
+		return locale[:idx]
+	}
+	return locale
+}
@@ -6,35 +6,41 @@ package gettext
 
 import (
 	"fmt"
+	"log"
 	"strings"
 )
 
-func (p *domainManager) bindDomainTranslators(domain, path string, data []byte) {
+func (p *domainManager) bindDomainTranslators(domain string, fs *fileSystem) {
 	if _, ok := p.domainMap[domain]; ok {
 		p.deleteDomain(domain) // delete old domain
 	}
-	fs := newFileSystem(path, data)
 	for locale, _ := range fs.LocaleMap {
-		trMapKey := p.makeTrMapKey(domain, locale)
-		if data, err := fs.LoadMessagesFile(domain, locale, ".mo"); err == nil {
-			p.trTextMap[trMapKey], _ = newMoTranslator(
-				fmt.Sprintf("%s_%s.mo", domain, locale),
-				data,
-			)
-			continue
-		}
-		if data, err := fs.LoadMessagesFile(domain, locale, ".po"); err == nil {
-			p.trTextMap[trMapKey], _ = newPoTranslator(
-				fmt.Sprintf("%s_%s.po", domain, locale),
-				data,
-			)
-			continue
-		}
-		p.trTextMap[p.makeTrMapKey(domain, locale)] = nilTranslator
+		p.trTextMap[p.makeTrMapKey(domain, locale)] = loadTranslator(fs, domain, locale)
 	}
 	p.domainMap[domain] = fs
 }
 
+func loadTranslator(fs *fileSystem, domain, locale string) *translator {
+	tr, err := loadTranslatorFile(fs, domain, locale)
+	if err != nil {
+		log.Printf("gettext-go: invalid translator, err = %v", err)
+		return nilTranslator
+	}
+	return tr
+}
+
+// loadTranslatorFile loads the mo or po file of the locale, it returns
+// nilTranslator if the locale has no messages file.
+func loadTranslatorFile(fs *fileSystem, domain, locale string) (*translator, error) {
+	if data, err := fs.LoadMessagesFile(domain, locale, ".mo"); err == nil {
+		return newMoReaderTranslator(fmt.Sprintf("%s_%s.mo", domain, locale), data)
+	}
+	if data, err := fs.LoadMessagesFile(domain, locale, ".po"); err == nil {
+		return newPoTranslator(fmt.Sprintf("%s_%s.po", domain, locale), data)
+	}
+	return nilTranslator, nil
+}
+
 func (p *domainManager) deleteDomain(domain string) {
 	if _, ok := p.domainMap[domain]; !ok {
 		return
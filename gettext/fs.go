@@ -8,44 +8,51 @@ import (
 	"archive/zip"
 	"bytes"
 	"fmt"
+	"io/fs"
 	"io/ioutil"
 	"log"
 	"os"
+	"path"
 	"strings"
 )
 
+// maxLocaleDirDepth limits the search of locale dirs,
+// the root of translations is at most two levels deep.
+const maxLocaleDirDepth = 3
+
 type fileSystem struct {
 	FsName    string
 	FsRoot    string
-	FsZipData []byte
+	Fs        fs.FS
 	LocaleMap map[string]bool
+	ZipFile   bool // the zip file of FsName is read
 }
 
 func newFileSystem(path string, data []byte) *fileSystem {
 	fs := &fileSystem{
-		FsName:    path,
-		FsZipData: data,
+		FsName: path,
 	}
-	if err := fs.init(); err != nil {
+	if err := fs.init(data); err != nil {
 		log.Printf("gettext-go: invalid domain, err = %v", err)
 	}
 	return fs
 }
 
-func (p *fileSystem) init() error {
-	zipName := func(name string) string {
-		if x := strings.LastIndexAny(name, `\/`); x != -1 {
-			name = name[x+1:]
-		}
-		name = strings.TrimSuffix(name, ".zip")
-		return name
+func newFileSystemFS(name string, fsys fs.FS) *fileSystem {
+	fs := &fileSystem{
+		FsName: name,
+		Fs:     fsys,
 	}
+	if err := fs.lsLocales(); err != nil {
+		log.Printf("gettext-go: invalid domain, err = %v", err)
+	}
+	return fs
+}
 
+func (p *fileSystem) init(data []byte) error {
 	// zip data
-	if len(p.FsZipData) != 0 {
-		p.FsRoot = zipName(p.FsName)
-		p.LocaleMap = p.lsZip(p.FsZipData)
-		return nil
+	if len(data) != 0 {
+		return p.initZip(data)
 	}
 
 	// local dir or zip file
@@ -56,133 +63,85 @@ func (p *fileSystem) init() error {
 
 	// local dir
 	if fi.IsDir() {
-		p.FsRoot = p.FsName
-		p.LocaleMap = p.lsDir(p.FsName)
-		return nil
+		p.Fs = os.DirFS(p.FsName)
+		return p.lsLocales()
 	}
 
 	// local zip file
-	p.FsZipData, err = ioutil.ReadFile(p.FsName)
+	data, err = ioutil.ReadFile(p.FsName)
 	if err != nil {
 		return err
 	}
-	p.FsRoot = zipName(p.FsName)
-	p.LocaleMap = p.lsZip(p.FsZipData)
-	return nil
+	p.ZipFile = true
+	return p.initZip(data)
 }
 
-func (p *fileSystem) LoadMessagesFile(domain, local, ext string) ([]byte, error) {
-	if len(p.FsZipData) == 0 {
-		trName := p.makeMessagesFileName(domain, local, ext)
-		rcData, err := ioutil.ReadFile(trName)
-		if err != nil {
-			return nil, err
-		}
-		return rcData, nil
-	} else {
-		r, err := zip.NewReader(bytes.NewReader(p.FsZipData), int64(len(p.FsZipData)))
-		if err != nil {
-			return nil, err
-		}
+func (p *fileSystem) initZip(data []byte) error {
+	r, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return err
+	}
+	p.Fs = r
+	return p.lsLocales()
+}
 
-		trName := p.makeMessagesFileName(domain, local, ext)
-		for _, f := range r.File {
-			if f.Name != trName {
-				continue
-			}
-			rc, err := f.Open()
-			if err != nil {
-				return nil, err
-			}
-			rcData, err := ioutil.ReadAll(rc)
-			rc.Close()
-			return rcData, err
-		}
-		return nil, fmt.Errorf("not found")
+func (p *fileSystem) LoadMessagesFile(domain, local, ext string) ([]byte, error) {
+	if p.Fs == nil {
+		return nil, fmt.Errorf("gettext: %s: invalid file system", p.FsName)
 	}
-	return nil, nil
+	return fs.ReadFile(p.Fs, p.makeMessagesFileName(domain, local, ext))
 }
 
 func (p *fileSystem) LoadResourceFile(domain, local, name string) ([]byte, error) {
-	if len(p.FsZipData) == 0 {
-		rcName := p.makeResourceFileName(domain, local, name)
-		rcData, err := ioutil.ReadFile(rcName)
-		if err != nil {
-			return nil, err
-		}
-		return rcData, nil
-	} else {
-		r, err := zip.NewReader(bytes.NewReader(p.FsZipData), int64(len(p.FsZipData)))
-		if err != nil {
-			return nil, err
-		}
-
-		rcName := p.makeResourceFileName(domain, local, name)
-		for _, f := range r.File {
-			if f.Name != rcName {
-				continue
-			}
-			rc, err := f.Open()
-			if err != nil {
-				return nil, err
-			}
-			rcData, err := ioutil.ReadAll(rc)
-			rc.Close()
-			return rcData, err
-		}
-		return nil, fmt.Errorf("not found")
+	if p.Fs == nil {
+		return nil, fmt.Errorf("gettext: %s: invalid file system", p.FsName)
 	}
+	return fs.ReadFile(p.Fs, p.makeResourceFileName(domain, local, name))
 }
 
 func (p *fileSystem) makeMessagesFileName(domain, local, ext string) string {
-	return fmt.Sprintf("%s/%s/LC_MESSAGES/%s%s", p.FsRoot, local, domain, ext)
+	return path.Join(p.FsRoot, local, "LC_MESSAGES", domain+ext)
 }
 
 func (p *fileSystem) makeResourceFileName(domain, local, name string) string {
-	return fmt.Sprintf("%s/%s/LC_RESOURCE/%s/%s", p.FsRoot, local, domain, name)
+	return path.Join(p.FsRoot, local, "LC_RESOURCE", domain, name)
 }
 
-func (p *fileSystem) lsZip(data []byte) map[string]bool {
-	r, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
-	if err != nil {
-		return nil
-	}
-	ssMap := make(map[string]bool)
-	for _, f := range r.File {
-		if x := strings.Index(f.Name, "LC_MESSAGES"); x != -1 {
-			s := strings.TrimRight(f.Name[:x], `\/`)
-			if x = strings.LastIndexAny(s, `\/`); x != -1 {
-				s = s[x+1:]
-			}
-			if s != "" {
-				ssMap[s] = true
-			}
-			continue
+// lsLocales finds the locales and the root dir of the file system.
+//
+// The locale is the parent dir of "LC_MESSAGES" or "LC_RESOURCE",
+// and the root is the parent dir of the first found locale:
+//	local/zh_CN/LC_MESSAGES/hello.mo -> root: local, locale: zh_CN
+//	zh_CN/LC_RESOURCE/hello/poems.txt -> root: ., locale: zh_CN
+func (p *fileSystem) lsLocales() error {
+	p.FsRoot = "."
+	p.LocaleMap = make(map[string]bool)
+
+	var hasRoot bool
+	return fs.WalkDir(p.Fs, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() || name == "." {
+			return nil
 		}
-		if x := strings.Index(f.Name, "LC_RESOURCE"); x != -1 {
-			s := strings.TrimRight(f.Name[:x], `\/`)
-			if x = strings.LastIndexAny(s, `\/`); x != -1 {
-				s = s[x+1:]
+		switch path.Base(name) {
+		case "LC_MESSAGES", "LC_RESOURCE":
+			localeDir := path.Dir(name)
+			if localeDir == "." {
+				return fs.SkipDir
 			}
-			if s != "" {
-				ssMap[s] = true
+			if !hasRoot {
+				p.FsRoot, hasRoot = path.Dir(localeDir), true
 			}
-			continue
+			if path.Dir(localeDir) == p.FsRoot {
+				p.LocaleMap[path.Base(localeDir)] = true
+			}
+			return fs.SkipDir
 		}
-	}
-	return ssMap
-}
-
-func (p *fileSystem) lsDir(path string) map[string]bool {
-	list, err := ioutil.ReadDir(path)
-	if err != nil {
-		return nil
-	}
-	ssMap := make(map[string]bool)
-	for _, dir := range list {
-		if dir.IsDir() {
-			ssMap[dir.Name()] = true
+		if strings.Count(name, "/") >= maxLocaleDirDepth {
+			return fs.SkipDir
 		}
-	}
-	return ssMap
+		return nil
+	})
 }
@@ -0,0 +1,88 @@
+// Copyright 2013 ChaiShushan <chaishushan{AT}gmail.com>. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gettext
+
+import (
+	"archive/zip"
+	"bytes"
+	"io/fs"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestFileSystem_FS(t *testing.T) {
+	zipReader, err := zip.NewReader(bytes.NewReader(testZipData), int64(len(testZipData)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, fsys := range map[string]fs.FS{
+		"os.DirFS":    os.DirFS("../examples/local"),
+		"zip.Reader":  zipReader,
+		"fstest.Root": testMapFS(t, ""),
+		"fstest.Sub":  testMapFS(t, "local/"),
+	} {
+		fs := newFileSystemFS(name, fsys)
+		if expect := map[string]bool{"default": true, "zh_CN": true, "zh_TW": true}; !reflect.DeepEqual(fs.LocaleMap, expect) {
+			t.Fatalf("%s: expect = %v, got = %v", name, expect, fs.LocaleMap)
+		}
+		if _, err := fs.LoadMessagesFile("hello", "zh_CN", ".po"); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if _, err := fs.LoadResourceFile("hello", "default", "favicon.ico"); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if _, err := fs.LoadResourceFile("hello", "zh_CN", "favicon.ico"); err == nil {
+			t.Fatalf("%s: expect error", name)
+		}
+	}
+}
+
+func TestBindTextdomainFS(t *testing.T) {
+	cat := NewCatalog()
+	cat.Textdomain("hello")
+
+	domains, paths := cat.BindTextdomainFS("hello", testMapFS(t, "local/"))
+	if len(domains) != 1 || domains[0] != "hello" || paths[0] != "fstest.MapFS" {
+		t.Fatalf("bad domains: %v, %v", domains, paths)
+	}
+	tr := cat.Locale("zh_TW")
+	if s := tr.PGettext("main.main", "Hello, world!"); s != "你好, 世界!" {
+		t.Fatalf("expect = %q, got = %q", "你好, 世界!", s)
+	}
+	if s := string(tr.Getdata("poems.txt")); !strings.Contains(s, "月下獨酌") {
+		t.Fatalf("bad poems.txt: %q", s)
+	}
+
+	if domains, _ := cat.BindTextdomainFS("hello", nil); len(domains) != 0 {
+		t.Fatalf("expect no domains, got = %v", domains)
+	}
+	if s := tr.PGettext("main.main", "Hello, world!"); s != "Hello, world!" {
+		t.Fatalf("expect = %q, got = %q", "Hello, world!", s)
+	}
+}
+
+// testMapFS returns the "../examples/local" files in a fstest.MapFS.
+func testMapFS(t *testing.T, prefix string) fstest.MapFS {
+	m := make(fstest.MapFS)
+	root := os.DirFS("../examples/local")
+	err := fs.WalkDir(root, ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		data, err := fs.ReadFile(root, name)
+		if err != nil {
+			return err
+		}
+		m[prefix+name] = &fstest.MapFile{Data: data}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return m
+}
@@ -4,14 +4,24 @@
 
 package gettext
 
+import (
+	"io/fs"
+	"time"
+)
+
 var (
-	defaultManager = newDomainManager()
+	defaultCatalog = NewCatalog()
 )
 
 var (
 	DefaultLocale = getDefaultLocale() // use $(LC_MESSAGES) or $(LANG) or "default"
 )
 
+// DefaultCatalog returns the Catalog of the package level functions.
+func DefaultCatalog() *Catalog {
+	return defaultCatalog
+}
+
 // SetLocale sets and queries the program's current locale.
 //
 // If the locale is not empty string, set the new local.
@@ -25,7 +35,38 @@ var (
 //	SetLocale("zh_CN") // set locale: return zh_CN
 //	SetLocale("")      // get locale: return zh_CN
 func SetLocale(locale string) string {
-	return defaultManager.SetLocale(locale)
+	return defaultCatalog.SetLocale(locale)
+}
+
+// SetLocaleFallback sets the fallback chain of the locale.
+//
+// The messages and resources are looked up one at a time along the
+// chain: the locale first, then the fallbacks, and "default" at last.
+// If the fallbacks are empty, the implicit chain is restored,
+// which is the locale, its language and "default".
+//
+// A language without territory matches the bound locales of that
+// language, so "zh" will find "zh_CN".
+//
+// Examples:
+//	SetLocaleFallback("pt_BR", "pt", "es") // pt_BR -> pt -> es -> default
+//	SetLocaleFallback("pt_BR")             // pt_BR -> pt -> default
+//	                                       // de_AT -> de -> default
+func SetLocaleFallback(locale string, fallbacks ...string) {
+	defaultCatalog.SetLocaleFallback(locale, fallbacks...)
+}
+
+// LocaleOf returns the locale which supplies the translation
+// of the message in the current domain and locale.
+//
+// If no locale in the fallback chain has the translation,
+// returns empty string.
+//
+// Examples:
+//	SetLocale("de_AT")
+//	LocaleOf("main.main", "Hello, world!") // return "de"
+func LocaleOf(msgctxt, msgid string) string {
+	return defaultCatalog.Locale("").LocaleOf(msgctxt, msgid)
 }
 
 // BindTextdomain sets and queries program's domains.
@@ -52,7 +93,79 @@ func SetLocale(locale string) string {
 //	BindTextdomain("poedit", "local.zip", zipData) // bind "poedit" domain
 //
 func BindTextdomain(domain, path string, zipData []byte) (domains, paths []string) {
-	return defaultManager.Bind(domain, path, zipData)
+	return defaultCatalog.BindTextdomain(domain, path, zipData)
+}
+
+// BindTextdomainFS like BindTextdomain(), but bind the domain to a file system.
+//
+// The file system has the same struct as the local dir, the root dir
+// of translations may be a sub dir(e.g. "local").
+//
+// If the fsys is nil, delete the domain.
+//
+// Examples:
+//	//go:embed local
+//	var localFS embed.FS
+//
+//	BindTextdomainFS("hello", localFS)            // from embedded files
+//	BindTextdomainFS("hello", os.DirFS("local"))  // from local dir
+//	BindTextdomainFS("hello", zipReader)          // from *zip.Reader
+//	BindTextdomainFS("hello", nil)                // delete "hello" domain
+func BindTextdomainFS(domain string, fsys fs.FS) (domains, paths []string) {
+	return defaultCatalog.BindTextdomainFS(domain, fsys)
+}
+
+// Locales returns the bound locales of the domain, in sorted order.
+//
+// If the domain is empty string, returns the locales of the current domain.
+//
+// Examples:
+//	Locales("hello") // return [default zh_CN zh_TW]
+func Locales(domain string) []string {
+	return defaultCatalog.Locales(domain)
+}
+
+// SetMissHandler sets the handler of the messages without translation,
+// nil removes the handler.
+//
+// The handler is called once for each domain, locale, msgctxt and msgid,
+// and at most 100 times per second, the dropped messages are reported
+// when they are looked up again. The reported messages are forgotten
+// after 10000 messages, and then they are reported again. The msgctxt
+// of Gettext is the caller's function name, so the handler knows where
+// the message is used.
+//
+// Examples:
+//	c := NewMissCollector()
+//	SetMissHandler(c.Handle)
+//	...
+//	c.File("hello").Save("missing.pot") // the messages to translate
+func SetMissHandler(h MissHandler) {
+	defaultCatalog.SetMissHandler(h)
+}
+
+// Watch polls the files of the bound domains every interval, and reloads
+// the translators of the changed mo and po files, until stop is called.
+//
+// Only the translators of the changed domain and locale are reloaded, and
+// they are swapped in at the same time, so a lookup sees either the old
+// or the new translations. The domains bound after Watch are watched too.
+// A local zip file is read again when it's changed, the embedded zip data
+// isn't watched.
+//
+// The fn is called for every reloaded locale, or with the error of the
+// reload, and the old translations are kept if the error is not nil.
+// The fn may be nil.
+//
+// Examples:
+//	stop := Watch(2*time.Second, func(ev ReloadEvent) {
+//		if ev.Err != nil {
+//			log.Printf("reload %s/%s: %v", ev.Domain, ev.Locale, ev.Err)
+//		}
+//	})
+//	defer stop()
+func Watch(interval time.Duration, fn func(ReloadEvent)) (stop func()) {
+	return defaultCatalog.Watch(interval, fn)
 }
 
 // Textdomain sets and retrieves the current message domain.
@@ -67,7 +180,7 @@ func BindTextdomain(domain, path string, zipData []byte) (domains, paths []strin
 //	Textdomain("poedit") // set domain: poedit
 //	Textdomain("")       // get domain: return poedit
 func Textdomain(domain string) string {
-	return defaultManager.SetDomain(domain)
+	return defaultCatalog.Textdomain(domain)
 }
 
 // Gettext attempt to translate a text string into the user's native language,
@@ -93,7 +206,7 @@ func Gettext(msgid string) string {
 //		poems := gettext.Getdata("poems.txt")
 //	}
 func Getdata(name string) []byte {
-	return defaultManager.Getdata(name)
+	return defaultCatalog.Locale("").Getdata(name)
 }
 
 // NGettext attempt to translate a text string into the user's native language,
@@ -130,7 +243,7 @@ func PGettext(msgctxt, msgid string) string {
 //		msg := gettext.PNGettext("gettext-go.example", "%d people", "%d peoples", 2)
 //	}
 func PNGettext(msgctxt, msgid, msgidPlural string, n int) string {
-	return defaultManager.PNGettext(msgctxt, msgid, msgidPlural, n)
+	return defaultCatalog.Locale("").PNGettext(msgctxt, msgid, msgidPlural, n)
 }
 
 // DGettext like Gettext(), but looking up the message in the specified domain.
@@ -170,5 +283,5 @@ func DPGettext(domain, msgctxt, msgid string) string {
 //		msg := gettext.DPNGettext("poedit", "gettext-go.example", "%d people", "%d peoples", 2)
 //	}
 func DPNGettext(domain, msgctxt, msgid, msgidPlural string, n int) string {
-	return defaultManager.DPNGettext(domain, msgctxt, msgid, msgidPlural, n)
+	return defaultCatalog.Locale("").DPNGettext(domain, msgctxt, msgid, msgidPlural, n)
 }
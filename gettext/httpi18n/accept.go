@@ -0,0 +1,103 @@
+// Copyright 2013 ChaiShushan <chaishushan{AT}gmail.com>. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package httpi18n
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// ParseAcceptLanguage returns the languages of the Accept-Language header,
+// sorted by the q-values. The languages of q=0 and "*" are dropped.
+//
+// Examples:
+//	ParseAcceptLanguage("da, en-GB;q=0.8, en;q=0.7") // [da en-GB en]
+//	ParseAcceptLanguage("en;q=0.5, zh-CN")            // [zh-CN en]
+func ParseAcceptLanguage(s string) []string {
+	type language struct {
+		tag string
+		q   float64
+	}
+	var langs []language
+	for _, part := range strings.Split(s, ",") {
+		fields := strings.Split(part, ";")
+		tag := strings.TrimSpace(fields[0])
+		if tag == "" || tag == "*" {
+			continue
+		}
+		q := 1.0
+		for _, param := range fields[1:] {
+			param = strings.TrimSpace(param)
+			if strings.HasPrefix(param, "q=") {
+				if v, err := strconv.ParseFloat(param[len("q="):], 64); err == nil {
+					q = v
+				}
+			}
+		}
+		if q > 0 {
+			langs = append(langs, language{tag, q})
+		}
+	}
+	sort.SliceStable(langs, func(i, j int) bool {
+		return langs[i].q > langs[j].q
+	})
+	tags := make([]string, len(langs))
+	for i, v := range langs {
+		tags[i] = v.tag
+	}
+	return tags
+}
+
+// Match returns the first locale which matches the languages in order,
+// or "" if nothing matches. The "default" locale doesn't match.
+//
+// A language matches the locale of the same name, like "zh-CN" and
+// "zh_CN", otherwise the locale of its language, and at last the first
+// locale of the same language:
+//	Match([]string{"de", "zh_CN"}, []string{"de-AT"}) // "de"
+//	Match([]string{"de", "zh_CN"}, []string{"zh"})    // "zh_CN"
+func Match(locales, languages []string) string {
+	for _, lang := range languages {
+		key := normalizeLocale(lang)
+		base := localeLanguage(key)
+		var best string
+		for _, locale := range locales {
+			if locale == "default" {
+				continue
+			}
+			switch s := normalizeLocale(locale); {
+			case s == key:
+				return locale
+			case s == base:
+				best = locale
+			case best == "" && localeLanguage(s) == base:
+				best = locale
+			}
+		}
+		if best != "" {
+			return best
+		}
+	}
+	return ""
+}
+
+// normalizeLocale returns the lower case locale without the charset,
+// and "_" is used as the separator:
+//	zh-CN       -> zh_cn
+//	en_US.UTF-8 -> en_us
+func normalizeLocale(s string) string {
+	if idx := strings.Index(s, "."); idx >= 0 {
+		s = s[:idx]
+	}
+	return strings.ToLower(strings.Replace(s, "-", "_", -1))
+}
+
+func localeLanguage(s string) string {
+	if idx := strings.IndexAny(s, "_@"); idx > 0 {
+		return s[:idx]
+	}
+	return s
+}
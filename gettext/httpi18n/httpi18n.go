@@ -0,0 +1,111 @@
+// Copyright 2013 ChaiShushan <chaishushan{AT}gmail.com>. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+/*
+Package httpi18n chooses the locale of http requests.
+
+The middleware chooses a locale of the domain for each request, from the
+query parameter, the cookie and the Accept-Language header in turn, and
+stores the Translator of the locale in the request context by
+gettext.NewContext. The global locale of SetLocale is not changed.
+
+Examples:
+	cat := gettext.NewCatalog()
+	cat.BindTextdomain("hello", "local", nil)
+	cat.Textdomain("hello")
+
+	http.Handle("/", httpi18n.Handler(cat, "hello", nil, http.HandlerFunc(hello)))
+
+	func hello(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(w, gettext.CtxGettext(r.Context(), "Hello, world!"))
+	}
+*/
+package httpi18n
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/faxal/gettext-go/gettext"
+)
+
+// Options are the options of the middleware.
+type Options struct {
+	QueryParam string // name of the query parameter of the locale, default is "lang"
+	Cookie     string // name of the cookie of the locale, default is "lang"
+	Default    string // locale if nothing matches, default is the catalog's current locale
+}
+
+// Handler returns a handler which chooses the locale of the request,
+// and calls h with the Translator of the locale in the request context.
+//
+// The locale is the first of the query parameter, the cookie and the
+// languages of the Accept-Language header by q-values, which matches
+// a bound locale of the domain. The Content-Language and Vary headers
+// of the response are set.
+//
+// If cat is nil, the catalog of the package level functions is used.
+func Handler(cat *gettext.Catalog, domain string, opts *Options, h http.Handler) http.Handler {
+	if cat == nil {
+		cat = gettext.DefaultCatalog()
+	}
+	if opts == nil {
+		opts = &Options{}
+	}
+	queryParam, cookie := opts.QueryParam, opts.Cookie
+	if queryParam == "" {
+		queryParam = "lang"
+	}
+	if cookie == "" {
+		cookie = "lang"
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		locales := cat.Locales(domain)
+		var prefs []string
+		if s := r.URL.Query().Get(queryParam); s != "" {
+			prefs = append(prefs, s)
+		}
+		if c, err := r.Cookie(cookie); err == nil && c.Value != "" {
+			prefs = append(prefs, c.Value)
+		}
+		prefs = append(prefs, ParseAcceptLanguage(r.Header.Get("Accept-Language"))...)
+
+		locale := Match(locales, prefs)
+		if locale == "" {
+			locale = opts.Default
+		}
+		tr := cat.Locale(locale)
+
+		w.Header().Add("Vary", "Accept-Language")
+		w.Header().Add("Vary", "Cookie")
+		if tag := languageTag(tr.Locale()); tag != "" {
+			w.Header().Set("Content-Language", tag)
+		}
+		h.ServeHTTP(w, r.WithContext(gettext.NewContext(r.Context(), tr)))
+	})
+}
+
+// Translator returns the Translator of the request, which is chosen by
+// Handler. If the request is not handled by Handler, returns the
+// Translator of the current locale of the package level functions.
+func Translator(r *http.Request) *gettext.Translator {
+	if tr, ok := gettext.FromContext(r.Context()); ok {
+		return tr
+	}
+	return gettext.DefaultCatalog().Locale("")
+}
+
+// languageTag returns the language tag of the locale for Content-Language:
+//	zh_CN       -> zh-CN
+//	en_US.UTF-8 -> en-US
+//	default     -> ""
+func languageTag(locale string) string {
+	if idx := strings.IndexAny(locale, ".@"); idx >= 0 {
+		locale = locale[:idx]
+	}
+	if locale == "default" {
+		return ""
+	}
+	return strings.Replace(locale, "_", "-", -1)
+}
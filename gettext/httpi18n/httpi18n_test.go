@@ -0,0 +1,101 @@
+// Copyright 2013 ChaiShushan <chaishushan{AT}gmail.com>. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package httpi18n
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/faxal/gettext-go/gettext"
+)
+
+func TestHandler(t *testing.T) {
+	cat := gettext.NewCatalog()
+	cat.BindTextdomain("hello", "../../examples/local", nil)
+	cat.Textdomain("hello")
+	cat.SetLocale("en_US")
+
+	h := Handler(cat, "hello", &Options{Default: "zh_CN"}, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(Translator(r).PGettext("main.main", "Hello, world!")))
+	}))
+	for i, v := range []struct {
+		url            string
+		cookie         string
+		acceptLanguage string
+		lang           string
+		text           string
+	}{
+		{"/", "", "", "zh-CN", "你好, 世界!"},
+		{"/", "", "zh-TW, zh-CN;q=0.9", "zh-TW", "你好, 世界!"},
+		{"/", "", "en, zh-tw;q=0.5, zh-CN;q=0.9", "zh-CN", "你好, 世界!"},
+		{"/", "", "de-AT, zh-HK;q=0.5", "zh-CN", "你好, 世界!"},
+		{"/", "lang=zh_TW", "zh-CN", "zh-TW", "你好, 世界!"},
+		{"/?lang=zh_TW", "lang=zh_CN", "zh-CN", "zh-TW", "你好, 世界!"},
+		{"/?lang=fr", "", "zh-TW;q=0", "zh-CN", "你好, 世界!"},
+	} {
+		r := httptest.NewRequest("GET", v.url, nil)
+		if v.cookie != "" {
+			r.Header.Set("Cookie", v.cookie)
+		}
+		if v.acceptLanguage != "" {
+			r.Header.Set("Accept-Language", v.acceptLanguage)
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		if got := w.Header().Get("Content-Language"); got != v.lang {
+			t.Fatalf("%d: expect = %q, got = %q", i, v.lang, got)
+		}
+		if got := w.Body.String(); got != v.text {
+			t.Fatalf("%d: expect = %q, got = %q", i, v.text, got)
+		}
+		if got := w.Header()["Vary"]; !reflect.DeepEqual(got, []string{"Accept-Language", "Cookie"}) {
+			t.Fatalf("%d: bad Vary: %q", i, got)
+		}
+	}
+	if got := cat.SetLocale(""); got != "en_US" {
+		t.Fatalf("the catalog's locale is changed to %q", got)
+	}
+}
+
+func TestParseAcceptLanguage(t *testing.T) {
+	for i, v := range []struct {
+		s      string
+		expect []string
+	}{
+		{"", []string{}},
+		{"zh-CN", []string{"zh-CN"}},
+		{"da, en-GB;q=0.8, en;q=0.7", []string{"da", "en-GB", "en"}},
+		{"en;q=0.5, zh-CN, *;q=0.1", []string{"zh-CN", "en"}},
+		{"de;q=0, fr;q=bad", []string{"fr"}},
+	} {
+		if got := ParseAcceptLanguage(v.s); !reflect.DeepEqual(got, v.expect) {
+			t.Fatalf("%d: expect = %q, got = %q", i, v.expect, got)
+		}
+	}
+}
+
+func TestMatch(t *testing.T) {
+	locales := []string{"de", "default", "pt_BR", "pt_PT", "zh_CN", "zh_TW"}
+	for i, v := range []struct {
+		languages []string
+		expect    string
+	}{
+		{nil, ""},
+		{[]string{"default"}, ""},
+		{[]string{"de-AT"}, "de"},
+		{[]string{"zh-tw"}, "zh_TW"},
+		{[]string{"zh"}, "zh_CN"},
+		{[]string{"pt"}, "pt_BR"},
+		{[]string{"pt-PT"}, "pt_PT"},
+		{[]string{"fr", "pt-AO"}, "pt_BR"},
+		{[]string{"zh_TW.UTF-8"}, "zh_TW"},
+	} {
+		if got := Match(locales, v.languages); got != v.expect {
+			t.Fatalf("%d: expect = %q, got = %q", i, v.expect, got)
+		}
+	}
+}
@@ -0,0 +1,233 @@
+// Copyright 2013 ChaiShushan <chaishushan{AT}gmail.com>. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gettext
+
+import (
+	"path"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/faxal/gettext-go/gettext/po"
+)
+
+// MissHandler is called when a message has no translation in the locale
+// and its fallbacks, and the msgid is returned.
+type MissHandler func(domain, locale, msgctxt, msgid, msgidPlural string)
+
+const (
+	maxMissRate = 100   // max number of the misses reported per second
+	maxMisses   = 10000 // max number of the remembered misses
+)
+
+// missFilter reports every miss once, and at most maxMissRate misses
+// per second. The dropped misses are reported when they happen again.
+//
+// The reported misses are forgotten after maxMisses misses, so the
+// memory is bounded, and they are reported again in the next window.
+type missFilter struct {
+	mutex   sync.Mutex
+	handler MissHandler
+	seen    map[string]bool
+	start   time.Time // start of the current second
+	count   int       // reported misses in the current second
+}
+
+func newMissFilter(h MissHandler) *missFilter {
+	return &missFilter{
+		handler: h,
+		seen:    make(map[string]bool),
+	}
+}
+
+func (p *missFilter) report(domain, locale, msgctxt, msgid, msgidPlural string) {
+	key := domain + "\x00" + locale + "\x00" + msgctxt + "\x04" + msgid
+	p.mutex.Lock()
+	if p.seen[key] {
+		p.mutex.Unlock()
+		return
+	}
+	if now := time.Now(); now.Sub(p.start) >= time.Second {
+		p.start, p.count = now, 0
+	}
+	if p.count >= maxMissRate {
+		p.mutex.Unlock()
+		return
+	}
+	if len(p.seen) >= maxMisses {
+		p.seen = make(map[string]bool)
+	}
+	p.seen[key] = true
+	p.count++
+	p.mutex.Unlock()
+
+	p.handler(domain, locale, msgctxt, msgid, msgidPlural)
+}
+
+// MissCollector collects the messages without translation, and writes
+// them as a po template file.
+//
+// The reference of a message is the caller of the translate function,
+// and the locales which miss the message are in the extracted comment.
+//
+// Examples:
+//	c := gettext.NewMissCollector()
+//	gettext.SetMissHandler(c.Handle)
+//	...
+//	c.File("hello").Save("missing.pot")
+type MissCollector struct {
+	mutex    sync.Mutex
+	messages []missMessage
+	index    map[string]int // index of messages, by domain, msgctxt and msgid
+}
+
+type missMessage struct {
+	domain  string
+	locales []string
+	po.Message
+}
+
+// NewMissCollector returns a new MissCollector.
+func NewMissCollector() *MissCollector {
+	return &MissCollector{
+		index: make(map[string]int),
+	}
+}
+
+// Handle records the message, it's a MissHandler.
+func (p *MissCollector) Handle(domain, locale, msgctxt, msgid, msgidPlural string) {
+	file, line := missCaller()
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	key := domain + "\x00" + msgctxt + "\x04" + msgid
+	i, ok := p.index[key]
+	if !ok {
+		i = len(p.messages)
+		p.index[key] = i
+		p.messages = append(p.messages, missMessage{domain: domain})
+		p.messages[i].MsgContext = msgctxt
+		p.messages[i].MsgId = msgid
+	}
+	m := &p.messages[i]
+	if m.MsgIdPlural == "" {
+		m.MsgIdPlural = msgidPlural
+	}
+	if !containsString(m.locales, locale) {
+		m.locales = append(m.locales, locale)
+		sort.Strings(m.locales)
+	}
+	if file != "" && !containsReference(&m.Comment, file, line) {
+		m.ReferenceFile = append(m.ReferenceFile, file)
+		m.ReferenceLine = append(m.ReferenceLine, line)
+	}
+}
+
+// Domains returns the domains of the collected messages, in sorted order.
+func (p *MissCollector) Domains() []string {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	var domains []string
+	for _, m := range p.messages {
+		if !containsString(domains, m.domain) {
+			domains = append(domains, m.domain)
+		}
+	}
+	sort.Strings(domains)
+	return domains
+}
+
+// File returns the collected messages of the domain as a po template
+// file, in the order of the first miss.
+//
+// If the domain is empty string, returns the messages of all domains.
+func (p *MissCollector) File(domain string) *po.File {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	f := &po.File{}
+	for _, m := range p.messages {
+		if domain != "" && m.domain != domain {
+			continue
+		}
+		msg := m.Message
+		msg.ReferenceFile = append([]string(nil), m.ReferenceFile...)
+		msg.ReferenceLine = append([]int(nil), m.ReferenceLine...)
+		msg.ExtractedComment = "missing in " + strings.Join(m.locales, ", ")
+		if domain == "" {
+			msg.ExtractedComment = "domain " + m.domain + ", " + msg.ExtractedComment
+		}
+		f.Messages = append(f.Messages, msg)
+	}
+	f.MimeHeader = po.NewTemplateHeader()
+	f.MimeHeader.POTCreationDate = time.Now().Format("2006-01-02 15:04-0700")
+	return f
+}
+
+// missCaller returns the reference of the first caller out of the gettext
+// packages, like "github.com/faxal/hello/hello.go" and the line.
+//
+// The frames of the standard packages are skipped too, so the caller of
+// text/template which runs the tmpli18n functions is the reference.
+func missCaller() (file string, line int) {
+	var pcs [64]uintptr
+	frames := runtime.CallersFrames(pcs[:runtime.Callers(2, pcs[:])])
+	for {
+		frame, more := frames.Next()
+		inPkg := strings.HasPrefix(frame.File, gettextSrcDir+"/") && !strings.HasSuffix(frame.File, "_test.go")
+		if pkg := funcPkgPath(frame.Function); !inPkg && !isStdPkg(pkg) {
+			return path.Join(pkg, path.Base(frame.File)), frame.Line
+		}
+		if !more {
+			return "", 0
+		}
+	}
+}
+
+// isStdPkg reports whether the package is a standard package, whose
+// first path element has no dot.
+func isStdPkg(pkg string) bool {
+	if i := strings.Index(pkg, "/"); i >= 0 {
+		pkg = pkg[:i]
+	}
+	return pkg != "main" && !strings.Contains(pkg, ".")
+}
+
+// gettextSrcDir is the source dir of the gettext package, the dirs of
+// the tmpli18n and httpi18n packages are under it.
+var gettextSrcDir = func() string {
+	_, file, _, _ := runtime.Caller(0)
+	return path.Dir(file)
+}()
+
+// funcPkgPath returns the package path of the function name.
+func funcPkgPath(name string) string {
+	i := strings.LastIndex(name, "/")
+	if j := strings.Index(name[i+1:], "."); j >= 0 {
+		return name[:i+1+j]
+	}
+	return name
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+func containsReference(c *po.Comment, file string, line int) bool {
+	for i := range c.ReferenceFile {
+		if c.ReferenceFile[i] == file && c.ReferenceLine[i] == line {
+			return true
+		}
+	}
+	return false
+}
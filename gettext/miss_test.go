@@ -0,0 +1,122 @@
+// Copyright 2013 ChaiShushan <chaishushan{AT}gmail.com>. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gettext
+
+import (
+	"fmt"
+	"reflect"
+	"runtime"
+	"testing"
+	"testing/fstest"
+	"time"
+)
+
+const testMissPoData = `msgid ""
+msgstr ""
+"Content-Type: text/plain; charset=UTF-8\n"
+"Plural-Forms: nplurals=2; plural=(n != 1);\n"
+
+msgctxt "github.com/faxal/gettext-go/gettext.TestMissCollector"
+msgid "Hello"
+msgstr "Hallo"
+`
+
+func TestMissCollector(t *testing.T) {
+	cat := NewCatalog()
+	cat.BindTextdomainFS("test", fstest.MapFS{
+		"de/LC_MESSAGES/test.po": &fstest.MapFile{Data: []byte(testMissPoData)},
+	})
+	cat.Textdomain("test")
+	c := NewMissCollector()
+	cat.SetMissHandler(c.Handle)
+
+	// the lines of the calls are the references, the msgctxt of the
+	// closures is given like the callers
+	const ctx = "github.com/faxal/gettext-go/gettext.TestMissCollector"
+	var deBye, frBye, deFile, deMenu int
+	de, fr := cat.Locale("de"), cat.Locale("fr")
+	for i := 0; i < 2; i++ {
+		de.Gettext("Hello")
+		deBye = testCallLine(func() { de.PGettext(ctx, "Bye") })
+		frBye = testCallLine(func() { fr.PGettext(ctx, "Bye") })
+		deFile = testCallLine(func() { de.PNGettext(ctx, "%d file", "%d files", 2) })
+	}
+	deMenu = testCallLine(func() { de.PGettext("menu", "File") })
+	de.DPGettext("other", "menu", "File")
+	cat.SetMissHandler(nil)
+	de.Gettext("Unreported")
+
+	if got, expect := c.Domains(), []string{"other", "test"}; !reflect.DeepEqual(got, expect) {
+		t.Fatalf("expect = %q, got = %q", expect, got)
+	}
+	expect := []struct {
+		msgctxt, msgid, msgidPlural, comment string
+		lines                                []int
+	}{
+		{ctx, "Bye", "", "missing in de, fr", []int{deBye, frBye}},
+		{ctx, "%d file", "%d files", "missing in de", []int{deFile}},
+		{"menu", "File", "", "missing in de", []int{deMenu}},
+	}
+	f := c.File("test")
+	if len(f.Messages) != len(expect) {
+		t.Fatalf("expect = %d messages, got = %v", len(expect), f.Messages)
+	}
+	for i, v := range f.Messages {
+		e := expect[i]
+		var files []string
+		for range e.lines {
+			files = append(files, "github.com/faxal/gettext-go/gettext/miss_test.go")
+		}
+		if v.MsgContext != e.msgctxt || v.MsgId != e.msgid || v.MsgIdPlural != e.msgidPlural ||
+			v.ExtractedComment != e.comment ||
+			!reflect.DeepEqual(v.ReferenceFile, files) || !reflect.DeepEqual(v.ReferenceLine, e.lines) {
+			t.Fatalf("%d: expect = %v, got = %#v", i, e, v)
+		}
+	}
+	if f := c.File(""); len(f.Messages) != 4 || f.Messages[3].ExtractedComment != "domain other, missing in de" {
+		t.Fatalf("bad messages: %v", f.Messages)
+	}
+}
+
+// testCallLine calls fn, and returns the line of the caller.
+func testCallLine(fn func()) int {
+	fn()
+	_, _, line, _ := runtime.Caller(1)
+	return line
+}
+
+func TestMissFilter(t *testing.T) {
+	var misses []string
+	p := newMissFilter(func(domain, locale, msgctxt, msgid, msgidPlural string) {
+		misses = append(misses, msgid)
+	})
+	for i := 0; i < maxMissRate+10; i++ {
+		p.report("test", "de", "", fmt.Sprint(i), "")
+		p.report("test", "de", "", fmt.Sprint(i), "")
+	}
+	if len(misses) != maxMissRate {
+		t.Fatalf("expect = %d misses, got = %d", maxMissRate, len(misses))
+	}
+
+	// the dropped misses are reported in the next second
+	p.start = p.start.Add(-time.Second)
+	for i := 0; i < maxMissRate+10; i++ {
+		p.report("test", "de", "", fmt.Sprint(i), "")
+	}
+	if len(misses) != maxMissRate+10 || misses[maxMissRate] != fmt.Sprint(maxMissRate) {
+		t.Fatalf("bad misses: %q", misses[maxMissRate:])
+	}
+
+	// the reported misses are forgotten after maxMisses misses
+	for i := len(p.seen); i < maxMisses; i++ {
+		p.seen[fmt.Sprint("x", i)] = true
+	}
+	p.start = p.start.Add(-time.Second)
+	p.report("test", "de", "", "new", "")
+	p.report("test", "de", "", "0", "")
+	if n := len(misses); n != maxMissRate+12 || misses[n-1] != "0" || len(p.seen) != 2 {
+		t.Fatalf("bad misses: %q", misses[maxMissRate+10:])
+	}
+}
@@ -16,6 +16,10 @@ Examples:
 			log.Fatal(err)
 		}
 		fmt.Printf("%v", moFile)
+
+		if msg, ok := moFile.Lookup("main.main", "Hello, world!"); ok {
+			fmt.Println(msg.MsgStr)
+		}
 	}
 
 GNU MO file struct:
@@ -68,6 +72,24 @@ GNU MO file struct:
 	             |                                          |
 	             +------------------------------------------+
 
+Use Reader to look up the messages of a large MO file, it decodes the
+entries only when they are looked up:
+
+	r, err := mo.NewReaderBytes(data)
+	if err != nil {
+		log.Fatal(err)
+	}
+	msg, ok := r.Lookup("main.main", "Hello, world!")
+
+The hashing table is the same as msgfmt's, use EncodeOptions.NoHashTable
+to leave it out.
+
+The strings are decoded to UTF-8 by the charset of the header, and are
+encoded to it again by Data. Use EncodeOptions.Charset to convert the
+file to another charset:
+
+	data := moFile.EncodeData(&mo.EncodeOptions{Charset: "UTF-8"})
+
 The GNU MO file specification is at
 http://www.gnu.org/software/gettext/manual/html_node/MO-Files.html.
 */
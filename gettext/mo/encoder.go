@@ -9,6 +9,8 @@ import (
 	"encoding/binary"
 	"sort"
 	"strings"
+
+	"github.com/faxal/gettext-go/gettext/charset"
 )
 
 type moHeader struct {
@@ -27,51 +29,87 @@ type moStrPos struct {
 	Addr uint32
 }
 
-func encodeFile(f *File) []byte {
+// EncodeOptions are the options of encoding a MO file.
+type EncodeOptions struct {
+	NoHashTable bool   // don't write the hashing table, like msgfmt --no-hash
+	Charset     string // charset of the strings, empty means the charset of the header
+}
+
+func encodeFile(f *File, opts *EncodeOptions) []byte {
+	if opts == nil {
+		opts = &EncodeOptions{}
+	}
 	hdr := &moHeader{
 		MagicNumber: MoMagicLittleEndian,
 	}
-	data := encodeData(hdr, f)
+	header := f.MimeHeader
+	enc := header.encoding()
+	if to := charset.Lookup(opts.Charset); opts.Charset != "" && to != nil && to != enc {
+		header.ContentType = charset.SetContentTypeCharset(header.ContentType, opts.Charset)
+		enc = to
+	}
+	data := encodeData(hdr, &header, f.Messages, enc, opts)
 	data = append(encodeHeader(hdr), data...)
 	return data
 }
 
 // encode data and init moHeader
-func encodeData(hdr *moHeader, f *File) []byte {
-	msgList := []Message{f.MimeHeader.toMessage()}
-	for _, v := range f.Messages {
+//
+// The layout is the same as msgfmt: the tables of original and
+// translation strings, the hashing table, the original strings and
+// the translation strings.
+func encodeData(hdr *moHeader, header *Header, messages []Message, enc *charset.Encoding, opts *EncodeOptions) []byte {
+	msgList := []Message{encodeMessage(enc, header.toMessage())}
+	for _, v := range messages {
 		if len(v.MsgId) == 0 {
 			continue
 		}
 		if len(v.MsgStr) == 0 && len(v.MsgStrPlural) == 0 {
 			continue
 		}
-		msgList = append(msgList, v)
+		msgList = append(msgList, encodeMessage(enc, v))
 	}
 	sort.Sort(byMessages(msgList))
 
-	var buf bytes.Buffer
+	var hashTable []uint32
+	if !opts.NoHashTable {
+		keys := make([]string, len(msgList))
+		for i, v := range msgList {
+			keys[i] = encodeMsgKey(v)
+		}
+		hashTable = makeHashTable(keys)
+	}
+
+	hdr.MsgIdCount = uint32(len(msgList))
+	hdr.MsgIdOffset = MoHeaderSize
+	hdr.MsgStrOffset = hdr.MsgIdOffset + uint32(len(msgList))*8
+	hdr.HashSize = uint32(len(hashTable))
+	hdr.HashOffset = hdr.MsgStrOffset + uint32(len(msgList))*8
+
+	var strBuf bytes.Buffer
+	var strOffset = hdr.HashOffset + hdr.HashSize*4
 	var msgIdPosList = make([]moStrPos, len(msgList))
 	var msgStrPosList = make([]moStrPos, len(msgList))
 	for i, v := range msgList {
-		// write msgid
 		msgId := encodeMsgId(v)
-		msgIdPosList[i].Addr = uint32(buf.Len() + MoHeaderSize)
+		msgIdPosList[i].Addr = strOffset + uint32(strBuf.Len())
 		msgIdPosList[i].Size = uint32(len(msgId))
-		buf.WriteString(msgId)
-		// write msgstr
+		strBuf.WriteString(msgId)
+		strBuf.WriteByte(0)
+	}
+	for i, v := range msgList {
 		msgStr := encodeMsgStr(v)
-		msgStrPosList[i].Addr = uint32(buf.Len() + MoHeaderSize)
+		msgStrPosList[i].Addr = strOffset + uint32(strBuf.Len())
 		msgStrPosList[i].Size = uint32(len(msgStr))
-		buf.WriteString(msgStr)
+		strBuf.WriteString(msgStr)
+		strBuf.WriteByte(0)
 	}
 
-	hdr.MsgIdOffset = uint32(buf.Len() + MoHeaderSize)
+	var buf bytes.Buffer
 	binary.Write(&buf, binary.LittleEndian, msgIdPosList)
-	hdr.MsgStrOffset = uint32(buf.Len() + MoHeaderSize)
 	binary.Write(&buf, binary.LittleEndian, msgStrPosList)
-
-	hdr.MsgIdCount = uint32(len(msgList))
+	binary.Write(&buf, binary.LittleEndian, hashTable)
+	buf.Write(strBuf.Bytes())
 	return buf.Bytes()
 }
 
@@ -82,6 +120,37 @@ func encodeHeader(hdr *moHeader) []byte {
 	return buf.Bytes()
 }
 
+// encodeMessage returns the message with the strings encoded to the
+// charset, the chars which the charset doesn't have are '?'.
+func encodeMessage(enc *charset.Encoding, v Message) Message {
+	if enc.IsUTF8() {
+		return v
+	}
+	encode := func(s string) string {
+		s, _ = enc.EncodeString(s)
+		return s
+	}
+	msg := Message{
+		MsgContext:  encode(v.MsgContext),
+		MsgId:       encode(v.MsgId),
+		MsgIdPlural: encode(v.MsgIdPlural),
+		MsgStr:      encode(v.MsgStr),
+	}
+	for _, s := range v.MsgStrPlural {
+		msg.MsgStrPlural = append(msg.MsgStrPlural, encode(s))
+	}
+	return msg
+}
+
+// encodeMsgKey returns the original string without msgid_plural,
+// which is the key of the hashing table and the sort order.
+func encodeMsgKey(v Message) string {
+	if v.MsgContext != "" {
+		return v.MsgContext + EotSeparator + v.MsgId
+	}
+	return v.MsgId
+}
+
 func encodeMsgId(v Message) string {
 	if v.MsgContext != "" && v.MsgIdPlural != "" {
 		return v.MsgContext + EotSeparator + v.MsgId + NulSeparator + v.MsgIdPlural
@@ -108,16 +177,7 @@ func (d byMessages) Len() int {
 	return len(d)
 }
 func (d byMessages) Less(i, j int) bool {
-	if a, b := d[i].MsgContext, d[j].MsgContext; a != b {
-		return a < b
-	}
-	if a, b := d[i].MsgId, d[j].MsgId; a != b {
-		return a < b
-	}
-	if a, b := d[i].MsgIdPlural, d[j].MsgIdPlural; a != b {
-		return a < b
-	}
-	return false
+	return encodeMsgKey(d[i]) < encodeMsgKey(d[j])
 }
 func (d byMessages) Swap(i, j int) {
 	d[i], d[j] = d[j], d[i]
@@ -0,0 +1,31 @@
+// Copyright 2013 ChaiShushan <chaishushan{AT}gmail.com>. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package mo
+
+import (
+	"fmt"
+)
+
+// FormatError reports that the MO file data is invalid.
+type FormatError struct {
+	Offset int64  // byte offset of the invalid data
+	Index  int    // index of the invalid entry, or -1
+	Msg    string // description of error
+}
+
+func (e *FormatError) Error() string {
+	if e.Index >= 0 {
+		return fmt.Sprintf("gettext: invalid MO file: %s of entry %d at offset %d", e.Msg, e.Index, e.Offset)
+	}
+	return fmt.Sprintf("gettext: invalid MO file: %s at offset %d", e.Msg, e.Offset)
+}
+
+// withIndex sets the entry index of the FormatError.
+func withIndex(err error, i int) error {
+	if e, ok := err.(*FormatError); ok && e.Index < 0 {
+		return &FormatError{Offset: e.Offset, Index: i, Msg: e.Msg}
+	}
+	return err
+}
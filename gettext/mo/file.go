@@ -6,8 +6,6 @@ package mo
 
 import (
 	"bytes"
-	"encoding/binary"
-	"fmt"
 	"io/ioutil"
 	"strings"
 )
@@ -35,6 +33,9 @@ type File struct {
 	HashOffset   uint32
 	MimeHeader   Header
 	Messages     []Message
+
+	hashTable   []uint32 // the hashing table of the loaded file
+	headerIndex int      // the header's index in the loaded file
 }
 
 // Load loads a named mo file.
@@ -47,118 +48,37 @@ func Load(name string) (*File, error) {
 }
 
 // LoadData loads mo file format data.
+//
+// The returned error is a *FormatError if the data is invalid.
 func LoadData(data []byte) (*File, error) {
-	r := bytes.NewReader(data)
-
-	var magicNumber uint32
-	if err := binary.Read(r, binary.LittleEndian, &magicNumber); err != nil {
-		return nil, fmt.Errorf("gettext: %v", err)
-	}
-	var bo binary.ByteOrder
-	switch magicNumber {
-	case MoMagicLittleEndian:
-		bo = binary.LittleEndian
-	case MoMagicBigEndian:
-		bo = binary.BigEndian
-	default:
-		return nil, fmt.Errorf("gettext: %v", "invalid magic number")
-	}
-
-	var header struct {
-		MajorVersion uint16
-		MinorVersion uint16
-		MsgIdCount   uint32
-		MsgIdOffset  uint32
-		MsgStrOffset uint32
-		HashSize     uint32
-		HashOffset   uint32
-	}
-	if err := binary.Read(r, bo, &header); err != nil {
-		return nil, fmt.Errorf("gettext: %v", err)
-	}
-	if v := header.MajorVersion; v != 0 && v != 1 {
-		return nil, fmt.Errorf("gettext: %v", "invalid version number")
-	}
-	if v := header.MinorVersion; v != 0 && v != 1 {
-		return nil, fmt.Errorf("gettext: %v", "invalid version number")
-	}
-
-	msgIdStart := make([]uint32, header.MsgIdCount)
-	msgIdLen := make([]uint32, header.MsgIdCount)
-	if _, err := r.Seek(int64(header.MsgIdOffset), 0); err != nil {
-		return nil, fmt.Errorf("gettext: %v", err)
-	}
-	for i := 0; i < int(header.MsgIdCount); i++ {
-		if err := binary.Read(r, bo, &msgIdLen[i]); err != nil {
-			return nil, fmt.Errorf("gettext: %v", err)
-		}
-		if err := binary.Read(r, bo, &msgIdStart[i]); err != nil {
-			return nil, fmt.Errorf("gettext: %v", err)
-		}
-	}
-
-	msgStrStart := make([]int32, header.MsgIdCount)
-	msgStrLen := make([]int32, header.MsgIdCount)
-	if _, err := r.Seek(int64(header.MsgStrOffset), 0); err != nil {
-		return nil, fmt.Errorf("gettext: %v", err)
-	}
-	for i := 0; i < int(header.MsgIdCount); i++ {
-		if err := binary.Read(r, bo, &msgStrLen[i]); err != nil {
-			return nil, fmt.Errorf("gettext: %v", err)
-		}
-		if err := binary.Read(r, bo, &msgStrStart[i]); err != nil {
-			return nil, fmt.Errorf("gettext: %v", err)
-		}
+	r, err := NewReaderBytes(data)
+	if err != nil {
+		return nil, err
 	}
+	hdr := data[:MoHeaderSize]
 
 	file := &File{
-		MagicNumber:  magicNumber,
-		MajorVersion: header.MajorVersion,
-		MinorVersion: header.MinorVersion,
-		MsgIdCount:   header.MsgIdCount,
-		MsgIdOffset:  header.MsgIdOffset,
-		MsgStrOffset: header.MsgStrOffset,
-		HashSize:     header.HashSize,
-		HashOffset:   header.HashOffset,
+		MagicNumber:  r.bo.Uint32(hdr),
+		MajorVersion: r.bo.Uint16(hdr[4:]),
+		MinorVersion: r.bo.Uint16(hdr[6:]),
+		MsgIdCount:   r.bo.Uint32(hdr[8:]),
+		MsgIdOffset:  r.bo.Uint32(hdr[12:]),
+		MsgStrOffset: r.bo.Uint32(hdr[16:]),
+		HashSize:     r.bo.Uint32(hdr[20:]),
+		HashOffset:   r.bo.Uint32(hdr[24:]),
+		Messages:     make([]Message, 0, r.Len()),
+		hashTable:    r.hashTable,
+		headerIndex:  -1,
 	}
-	for i := 0; i < int(header.MsgIdCount); i++ {
-		if _, err := r.Seek(int64(msgIdStart[i]), 0); err != nil {
-			return nil, fmt.Errorf("gettext: %v", err)
-		}
-		msgIdData := make([]byte, msgIdLen[i])
-		if _, err := r.Read(msgIdData); err != nil {
-			return nil, fmt.Errorf("gettext: %v", err)
-		}
-
-		if _, err := r.Seek(int64(msgStrStart[i]), 0); err != nil {
-			return nil, fmt.Errorf("gettext: %v", err)
-		}
-		msgStrData := make([]byte, msgStrLen[i])
-		if _, err := r.Read(msgStrData); err != nil {
-			return nil, fmt.Errorf("gettext: %v", err)
+	for i := 0; i < r.Len(); i++ {
+		msg, err := r.Message(i)
+		if err != nil {
+			return nil, err
 		}
-
-		if len(msgIdData) == 0 {
-			var msg = Message{
-				MsgId:  string(msgIdData),
-				MsgStr: string(msgStrData),
-			}
+		if msg.MsgId == "" && msg.MsgContext == "" && msg.MsgIdPlural == "" {
 			file.MimeHeader.fromMessage(&msg)
+			file.headerIndex = i
 		} else {
-			var msg = Message{
-				MsgId:  string(msgIdData),
-				MsgStr: string(msgStrData),
-			}
-			// Is this a context message?
-			if idx := strings.Index(msg.MsgId, EotSeparator); idx != -1 {
-				msg.MsgContext, msg.MsgId = msg.MsgId[:idx], msg.MsgId[idx+1:]
-			}
-			// Is this a plural message?
-			if idx := strings.Index(msg.MsgId, NulSeparator); idx != -1 {
-				msg.MsgId, msg.MsgIdPlural = msg.MsgId[:idx], msg.MsgId[idx+1:]
-				msg.MsgStrPlural = strings.Split(msg.MsgStr, NulSeparator)
-				msg.MsgStr = ""
-			}
 			file.Messages = append(file.Messages, msg)
 		}
 	}
@@ -172,22 +92,73 @@ func (f *File) Save(name string) error {
 }
 
 // Save returns a mo file format data.
+//
+// The strings are encoded to the charset of the header, the chars
+// which the charset doesn't have are written as '?'.
 func (f *File) Data() []byte {
-	return encodeFile(f)
+	return encodeFile(f, nil)
+}
+
+// EncodeData returns a mo file format data with the options.
+func (f *File) EncodeData(opts *EncodeOptions) []byte {
+	return encodeFile(f, opts)
 }
 
-// String returns the po format file string.
+// Lookup finds the message with the msgctxt and msgid.
+//
+// It uses the hashing table of the loaded file if it exists,
+// otherwise a binary search over the messages, which must be
+// sorted as the original strings of MO file.
+func (f *File) Lookup(msgctxt, msgid string) (msg Message, ok bool) {
+	key := encodeMsgKey(Message{MsgContext: msgctxt, MsgId: msgid})
+	if key == "" {
+		return Message{}, false
+	}
+	// the original strings are compared in the charset of the file
+	enc := f.MimeHeader.encoding()
+	key, err := enc.EncodeString(key)
+	if err != nil {
+		return Message{}, false // the charset doesn't have the key
+	}
+	msgKey := func(i int) string {
+		s, _ := enc.EncodeString(encodeMsgKey(f.Messages[i]))
+		return s
+	}
+
+	var idx int
+	if f.hashTable != nil && len(f.Messages) == int(f.MsgIdCount)-1 && f.headerIndex >= 0 {
+		// the file index includes the header entry
+		idx = hashLookup(f.hashTable, int(f.MsgIdCount), key, func(i int) int {
+			switch {
+			case i == f.headerIndex:
+				return strings.Compare("", key)
+			case i > f.headerIndex:
+				i--
+			}
+			return strings.Compare(msgKey(i), key)
+		})
+		if idx > f.headerIndex {
+			idx--
+		}
+	} else {
+		idx = binaryLookup(len(f.Messages), func(i int) int {
+			return strings.Compare(msgKey(i), key)
+		})
+	}
+	if idx < 0 {
+		return Message{}, false
+	}
+	return f.Messages[idx], true
+}
+
+// String returns the po format file string in UTF-8, the charset of
+// the header is used by Data and EncodeData only.
 func (f *File) String() string {
 	var buf bytes.Buffer
-	fmt.Fprintf(&buf, "# version: %d.%d\n", f.MajorVersion, f.MinorVersion)
-	fmt.Fprintf(&buf, "%s\n", f.MimeHeader.String())
-	fmt.Fprintf(&buf, "\n")
-
-	for k, v := range f.Messages {
-		fmt.Fprintf(&buf, `msgid "%s"`+"\n", k)
-		fmt.Fprintf(&buf, `msgstr "%s"`+"\n", v.MsgStr)
-		fmt.Fprintf(&buf, "\n")
+	buf.WriteString(f.MimeHeader.String())
+	for _, v := range f.Messages {
+		buf.WriteString("\n")
+		buf.WriteString(v.String())
 	}
-
 	return buf.String()
 }
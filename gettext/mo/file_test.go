@@ -5,9 +5,101 @@
 package mo
 
 import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
 	"testing"
 )
 
 func TestFile(t *testing.T) {
 	//
 }
+
+func TestFile_String(t *testing.T) {
+	f := &File{
+		MimeHeader: ParseHeader("Language: de\nPlural-Forms: nplurals=2; plural=(n != 1);\n"),
+		Messages: []Message{
+			{MsgContext: "main.main", MsgId: "Hello, world!", MsgStr: "Hallo, Welt!"},
+			{MsgId: "%d file", MsgIdPlural: "%d files", MsgStrPlural: []string{"%d Datei", "%d Dateien"}},
+			{MsgId: "Line 1\nLine 2", MsgStr: "Zeile 1\n\"Zeile 2\"\n"},
+		},
+	}
+	if got := f.String(); got != testFileString {
+		t.Fatalf("expect = %q, got = %q", testFileString, got)
+	}
+}
+
+func TestFile_String_charset(t *testing.T) {
+	f := &File{
+		MimeHeader: ParseHeader("Content-Type: text/plain; charset=ISO-8859-1\n"),
+		Messages:   []Message{{MsgId: "Hello", MsgStr: "Grüße, 世界"}},
+	}
+	// the chars which ISO-8859-1 doesn't have are kept
+	if s := f.String(); !strings.Contains(s, `msgstr "Grüße, 世界"`) {
+		t.Fatalf("bad string: %q", s)
+	}
+}
+
+const testFileString = `msgid ""
+msgstr ""
+"Language: de\n"
+"Plural-Forms: nplurals=2; plural=(n != 1);\n"
+
+msgctxt "main.main"
+msgid "Hello, world!"
+msgstr "Hallo, Welt!"
+
+msgid "%d file"
+msgid_plural "%d files"
+msgstr[0] "%d Datei"
+msgstr[1] "%d Dateien"
+
+msgid ""
+"Line 1\n"
+"Line 2"
+msgstr ""
+"Zeile 1\n"
+"\"Zeile 2\"\n"
+`
+
+func FuzzLoadData(f *testing.F) {
+	names, err := filepath.Glob("../../testdata/*.mo")
+	if err != nil {
+		f.Fatal(err)
+	}
+	for _, name := range names {
+		data, err := ioutil.ReadFile(name)
+		if err != nil {
+			f.Fatal(err)
+		}
+		f.Add(data)
+	}
+	f.Add(testMoFile.Data())
+	f.Add(testMoFile.EncodeData(&EncodeOptions{NoHashTable: true}))
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		file, err := LoadData(data)
+		if err != nil {
+			if _, ok := err.(*FormatError); !ok {
+				t.Fatalf("expect *FormatError, got = %v", err)
+			}
+			return
+		}
+		r, err := NewReaderBytes(data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i := 0; i < r.Len(); i++ {
+			if _, err := r.Message(i); err != nil {
+				t.Fatalf("%d: %v", i, err)
+			}
+		}
+		for _, v := range file.Messages {
+			file.Lookup(v.MsgContext, v.MsgId)
+			r.Lookup(v.MsgContext, v.MsgId)
+		}
+		if _, err := LoadData(file.Data()); err != nil {
+			t.Fatal(err)
+		}
+	})
+}
@@ -0,0 +1,127 @@
+// Copyright 2013 ChaiShushan <chaishushan{AT}gmail.com>. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package mo
+
+// hashString is the hashpjw function used by GNU's gettext.
+//
+// See GNU's gettext library source code: gettext/gettext-runtime/intl/hash-string.c
+func hashString(s string) uint32 {
+	const hashWordBits = 32
+	var hval, g uint32
+	for i := 0; i < len(s) && s[i] != 0; i++ {
+		hval <<= 4
+		hval += uint32(s[i])
+		g = hval & (0xf << (hashWordBits - 4))
+		if g != 0 {
+			hval ^= g >> (hashWordBits - 8)
+			hval ^= g
+		}
+	}
+	return hval
+}
+
+// hashTableSize returns the hashing table size of n strings,
+// as msgfmt does: the next prime of n*4/3, and at least 3.
+func hashTableSize(n int) uint32 {
+	size := nextPrime(uint32(n * 4 / 3))
+	if size <= 2 {
+		size = 3
+	}
+	return size
+}
+
+// nextPrime and isPrime are the same as GNU's gettext,
+// so the table size is the same as msgfmt's(e.g. 3 is not a prime).
+//
+// See GNU's gettext library source code: gettext/gettext-tools/gnulib-lib/hash.c
+func nextPrime(seed uint32) uint32 {
+	seed |= 1 // make it definitely odd
+	for !isPrime(seed) {
+		seed += 2
+	}
+	return seed
+}
+
+func isPrime(candidate uint32) bool {
+	divn := uint32(3)
+	sq := divn * divn
+	for sq < candidate && candidate%divn != 0 {
+		divn++
+		sq += 4 * divn
+		divn++
+	}
+	return candidate%divn != 0
+}
+
+// makeHashTable returns the open addressing hashing table of the
+// sorted original strings. The table holds the string index plus one,
+// and zero for the empty slot.
+func makeHashTable(keys []string) []uint32 {
+	size := hashTableSize(len(keys))
+	table := make([]uint32, size)
+	for i, key := range keys {
+		hval := hashString(key)
+		idx := hval % size
+		incr := 1 + hval%(size-2)
+		for table[idx] != 0 {
+			if idx >= size-incr {
+				idx -= size - incr
+			} else {
+				idx += incr
+			}
+		}
+		table[idx] = uint32(i) + 1
+	}
+	return table
+}
+
+// hashLookup returns the index of the key in the hashing table,
+// cmp(i) compares the i-th original string with the key.
+//
+// Returns -1 if the key is not found.
+func hashLookup(table []uint32, count int, key string, cmp func(i int) int) int {
+	size := uint32(len(table))
+	if size <= 2 {
+		return -1
+	}
+	hval := hashString(key)
+	idx := hval % size
+	incr := 1 + hval%(size-2)
+	for i := uint32(0); i < size; i++ {
+		nstr := table[idx]
+		if nstr == 0 {
+			return -1
+		}
+		if n := int(nstr - 1); n < count && cmp(n) == 0 {
+			return n
+		}
+		if idx >= size-incr {
+			idx -= size - incr
+		} else {
+			idx += incr
+		}
+	}
+	return -1
+}
+
+// binaryLookup returns the index of the key in the sorted original strings,
+// cmp(i) compares the i-th original string with the key.
+//
+// Returns -1 if the key is not found.
+func binaryLookup(count int, cmp func(i int) int) int {
+	lo, hi := 0, count
+	for lo < hi {
+		mid := int(uint(lo+hi) >> 1)
+		switch c := cmp(mid); {
+		case c == 0:
+			return mid
+		case c < 0:
+			lo = mid + 1
+		default:
+			hi = mid
+		}
+	}
+	return -1
+}
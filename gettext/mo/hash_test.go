@@ -0,0 +1,96 @@
+// Copyright 2013 ChaiShushan <chaishushan{AT}gmail.com>. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package mo
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestHashTable_msgfmt(t *testing.T) {
+	names, err := filepath.Glob("../../testdata/*.mo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(names) == 0 {
+		t.Fatal("no testdata")
+	}
+	for _, name := range names {
+		data, err := ioutil.ReadFile(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var hdr moHeader
+		if err := binary.Read(bytes.NewReader(data), binary.LittleEndian, &hdr); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if hdr.MagicNumber != MoMagicLittleEndian || hdr.HashSize == 0 {
+			continue
+		}
+		keys := make([]string, hdr.MsgIdCount)
+		for i := range keys {
+			var pos moStrPos
+			r := bytes.NewReader(data[hdr.MsgIdOffset+uint32(i)*8:])
+			if err := binary.Read(r, binary.LittleEndian, &pos); err != nil {
+				t.Fatalf("%s: %v", name, err)
+			}
+			keys[i] = strings.SplitN(string(data[pos.Addr:pos.Addr+pos.Size]), NulSeparator, 2)[0]
+		}
+		expect := make([]uint32, hdr.HashSize)
+		r := bytes.NewReader(data[hdr.HashOffset:])
+		if err := binary.Read(r, binary.LittleEndian, expect); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if got := makeHashTable(keys); !reflect.DeepEqual(got, expect) {
+			t.Fatalf("%s: expect = %v, got = %v", name, expect, got)
+		}
+	}
+}
+
+func TestFile_Lookup(t *testing.T) {
+	for _, opts := range []*EncodeOptions{nil, {NoHashTable: true}} {
+		f, err := LoadData(testMoFile.EncodeData(opts))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if hasTable := f.hashTable != nil; hasTable == (opts != nil) {
+			t.Fatalf("bad hash table: %v", f.hashTable)
+		}
+		for _, v := range testMoFile.Messages {
+			msg, ok := f.Lookup(v.MsgContext, v.MsgId)
+			if !ok || !reflect.DeepEqual(&msg, &v) {
+				t.Fatalf("expect = %v, got = %v", v, msg)
+			}
+		}
+		if _, ok := f.Lookup("main.main", "Gettext in init."); ok {
+			t.Fatal("expect not found")
+		}
+		if _, ok := f.Lookup("", ""); ok {
+			t.Fatal("expect header not found")
+		}
+	}
+}
+
+func TestFile_EncodeData_msgfmt(t *testing.T) {
+	data, err := ioutil.ReadFile("../../testdata/gettext-4.mo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, err := LoadData(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.HashSize == 0 {
+		t.Fatal("expect hash table")
+	}
+	if got := f.Data(); !bytes.Equal(got, data) {
+		t.Fatalf("expect the same data as msgfmt:\n%q\n%q", data, got)
+	}
+}
@@ -7,7 +7,10 @@ package mo
 import (
 	"bytes"
 	"fmt"
+	"sort"
 	"strings"
+
+	"github.com/faxal/gettext-go/gettext/charset"
 )
 
 // Header is the initial comments "SOME DESCRIPTIVE TITLE", "YEAR"
@@ -28,6 +31,37 @@ type Header struct {
 	PluralForms             string // Plural-Forms: nplurals=2; plural=n == 1 ? 0 : 1;
 	XGenerator              string // X-Generator: Poedit 1.5.5
 	UnknowFields            map[string]string
+
+	fieldOrder []string // the field names of the parsed header, in order
+}
+
+// headerFields are the known header fields, in GNU's order.
+var headerFields = []struct {
+	name  string
+	value func(p *Header) *string
+}{
+	{"Project-Id-Version", func(p *Header) *string { return &p.ProjectIdVersion }},
+	{"Report-Msgid-Bugs-To", func(p *Header) *string { return &p.ReportMsgidBugsTo }},
+	{"POT-Creation-Date", func(p *Header) *string { return &p.POTCreationDate }},
+	{"PO-Revision-Date", func(p *Header) *string { return &p.PORevisionDate }},
+	{"Last-Translator", func(p *Header) *string { return &p.LastTranslator }},
+	{"Language-Team", func(p *Header) *string { return &p.LanguageTeam }},
+	{"Language", func(p *Header) *string { return &p.Language }},
+	{"MIME-Version", func(p *Header) *string { return &p.MimeVersion }},
+	{"Content-Type", func(p *Header) *string { return &p.ContentType }},
+	{"Content-Transfer-Encoding", func(p *Header) *string { return &p.ContentTransferEncoding }},
+	{"Plural-Forms", func(p *Header) *string { return &p.PluralForms }},
+	{"X-Generator", func(p *Header) *string { return &p.XGenerator }},
+}
+
+// ParseHeader parses the msgstr of the header entry.
+//
+// The fields keep their order when the header is encoded,
+// like msgfmt copies the header entry.
+func ParseHeader(msgstr string) Header {
+	var p Header
+	p.fromMessage(&Message{MsgStr: msgstr})
+	return p
 }
 
 func (p *Header) fromMessage(msg *Message) {
@@ -42,68 +76,100 @@ func (p *Header) fromMessage(msg *Message) {
 		}
 		key := strings.TrimSpace(lines[i][:idx])
 		val := strings.TrimSpace(lines[i][idx+1:])
-		switch strings.ToUpper(key) {
-		case strings.ToUpper("Project-Id-Version"):
-			p.ProjectIdVersion = val
-		case strings.ToUpper("Report-Msgid-Bugs-To"):
-			p.ReportMsgidBugsTo = val
-		case strings.ToUpper("POT-Creation-Date"):
-			p.POTCreationDate = val
-		case strings.ToUpper("PO-Revision-Date"):
-			p.PORevisionDate = val
-		case strings.ToUpper("Last-Translator"):
-			p.LastTranslator = val
-		case strings.ToUpper("Language-Team"):
-			p.LanguageTeam = val
-		case strings.ToUpper("Language"):
-			p.Language = val
-		case strings.ToUpper("MIME-Version"):
-			p.MimeVersion = val
-		case strings.ToUpper("Content-Type"):
-			p.ContentType = val
-		case strings.ToUpper("Content-Transfer-Encoding"):
-			p.ContentTransferEncoding = val
-		case strings.ToUpper("Plural-Forms"):
-			p.PluralForms = val
-		case strings.ToUpper("X-Generator"):
-			p.XGenerator = val
-		default:
-			if p.UnknowFields == nil {
-				p.UnknowFields = make(map[string]string)
-			}
-			p.UnknowFields[key] = val
+		p.fieldOrder = append(p.fieldOrder, key)
+		if v := p.field(key); v != nil {
+			*v = val
+			continue
 		}
+		if p.UnknowFields == nil {
+			p.UnknowFields = make(map[string]string)
+		}
+		p.UnknowFields[key] = val
 	}
 }
 
+// field returns the known field of the name, or nil.
+func (p *Header) field(name string) *string {
+	for _, f := range headerFields {
+		if strings.EqualFold(f.name, name) {
+			return f.value(p)
+		}
+	}
+	return nil
+}
+
+// Charset returns the charset of the Content-Type field, like "UTF-8".
+func (p *Header) Charset() string {
+	return charset.ContentTypeCharset(p.ContentType)
+}
+
+// encoding returns the encoding of the charset, unknown charsets are
+// not converted like UTF-8.
+func (p *Header) encoding() *charset.Encoding {
+	if enc := charset.Lookup(p.Charset()); enc != nil {
+		return enc
+	}
+	return charset.UTF8
+}
+
 func (p *Header) toMessage() Message {
 	return Message{
-		MsgStr: p.String(),
+		MsgStr: p.msgStr(),
 	}
 }
 
-// String returns the po format header string.
-func (p Header) String() string {
+// msgStr returns the header entry's msgstr.
+//
+// The fields of the parsed header keep their order, and the other
+// fields which are not empty are appended in GNU's order.
+func (p *Header) msgStr() string {
 	var buf bytes.Buffer
-	fmt.Fprintf(&buf, `msgid ""`+"\n")
-	fmt.Fprintf(&buf, `msgstr ""`+"\n")
-	fmt.Fprintf(&buf, `"%s: %s\n"`+"\n", "Project-Id-Version", p.ProjectIdVersion)
-	fmt.Fprintf(&buf, `"%s: %s\n"`+"\n", "Report-Msgid-Bugs-To", p.ReportMsgidBugsTo)
-	fmt.Fprintf(&buf, `"%s: %s\n"`+"\n", "POT-Creation-Date", p.POTCreationDate)
-	fmt.Fprintf(&buf, `"%s: %s\n"`+"\n", "PO-Revision-Date", p.PORevisionDate)
-	fmt.Fprintf(&buf, `"%s: %s\n"`+"\n", "Last-Translator", p.LastTranslator)
-	fmt.Fprintf(&buf, `"%s: %s\n"`+"\n", "Language-Team", p.LanguageTeam)
-	fmt.Fprintf(&buf, `"%s: %s\n"`+"\n", "Language", p.Language)
-	if p.MimeVersion != "" {
-		fmt.Fprintf(&buf, `"%s: %s\n"`+"\n", "MIME-Version", p.MimeVersion)
+	done := make(map[string]bool)
+	for _, key := range p.fieldOrder {
+		name := strings.ToUpper(key)
+		if done[name] {
+			continue
+		}
+		if v := p.field(key); v != nil {
+			fmt.Fprintf(&buf, "%s: %s\n", key, *v)
+		} else if v, ok := p.UnknowFields[key]; ok {
+			fmt.Fprintf(&buf, "%s: %s\n", key, v)
+		} else {
+			continue
+		}
+		done[name] = true
+	}
+	for _, f := range headerFields {
+		if v := *f.value(p); v != "" && !done[strings.ToUpper(f.name)] {
+			fmt.Fprintf(&buf, "%s: %s\n", f.name, v)
+		}
+	}
+	var keys []string
+	for k := range p.UnknowFields {
+		if !done[strings.ToUpper(k)] {
+			keys = append(keys, k)
+		}
 	}
-	fmt.Fprintf(&buf, `"%s: %s\n"`+"\n", "Content-Type", p.ContentType)
-	fmt.Fprintf(&buf, `"%s: %s\n"`+"\n", "Content-Transfer-Encoding", p.ContentTransferEncoding)
-	if p.XGenerator != "" {
-		fmt.Fprintf(&buf, `"%s: %s\n"`+"\n", "X-Generator", p.XGenerator)
+	sort.Strings(keys)
+	for _, k := range keys {
+		fmt.Fprintf(&buf, "%s: %s\n", k, p.UnknowFields[k])
 	}
-	for k, v := range p.UnknowFields {
-		fmt.Fprintf(&buf, `"%s: %s\n"`+"\n", k, v)
+	return buf.String()
+}
+
+// MsgStr returns the msgstr of the header entry, it's the inverse
+// of ParseHeader.
+func (p *Header) MsgStr() string {
+	return p.msgStr()
+}
+
+// String returns the po format header string.
+func (p Header) String() string {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "msgid \"\"\n")
+	fmt.Fprintf(&buf, "msgstr \"\"\n")
+	if s := p.msgStr(); s != "" {
+		buf.WriteString(encodePoString(s))
 	}
 	return buf.String()
 }
@@ -8,6 +8,21 @@ import (
 	"testing"
 )
 
+func TestParseHeader(t *testing.T) {
+	msgstr := "Project-Id-Version: hello\n" +
+		"Report-Msgid-Bugs-To: \n" +
+		"Content-Type: text/plain; charset=UTF-8\n" +
+		"X-Poedit-SourceCharset: UTF-8\n" +
+		"Language: zh_CN\n"
+	h := ParseHeader(msgstr)
+	if h.Language != "zh_CN" || h.UnknowFields["X-Poedit-SourceCharset"] != "UTF-8" {
+		t.Fatalf("bad header: %#v", h)
+	}
+	if got := h.msgStr(); got != msgstr {
+		t.Fatalf("expect = %q, got = %q", msgstr, got)
+	}
+}
+
 func TestHeader(t *testing.T) {
 	//
 }
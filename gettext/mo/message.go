@@ -25,15 +25,17 @@ type Message struct {
 // String returns the po format entry string.
 func (p Message) String() string {
 	var buf bytes.Buffer
-	fmt.Fprintf(&buf, "msgid %s", encodePoString(p.MsgId))
-	if p.MsgIdPlural != "" {
-		fmt.Fprintf(&buf, "msgid_plural %s", encodePoString(p.MsgIdPlural))
+	if p.MsgContext != "" {
+		writePoString(&buf, "msgctxt", p.MsgContext)
 	}
-	if p.MsgStr != "" {
-		fmt.Fprintf(&buf, "msgstr %s", encodePoString(p.MsgStr))
+	writePoString(&buf, "msgid", p.MsgId)
+	if p.MsgIdPlural == "" {
+		writePoString(&buf, "msgstr", p.MsgStr)
+		return buf.String()
 	}
+	writePoString(&buf, "msgid_plural", p.MsgIdPlural)
 	for i := 0; i < len(p.MsgStrPlural); i++ {
-		fmt.Fprintf(&buf, "msgstr[%d] %s", i, encodePoString(p.MsgStrPlural[i]))
+		writePoString(&buf, fmt.Sprintf("msgstr[%d]", i), p.MsgStrPlural[i])
 	}
 	return buf.String()
 }
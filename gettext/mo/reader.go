@@ -0,0 +1,356 @@
+// Copyright 2013 ChaiShushan <chaishushan{AT}gmail.com>. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package mo
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/faxal/gettext-go/gettext/charset"
+)
+
+// Reader is a lazily decoded MO file.
+//
+// Unlike LoadData, a Reader doesn't copy the strings of the file:
+// the original strings are compared in place, and only the looked up
+// entry is decoded. The strings are decoded to UTF-8 by the charset
+// of the header, like LoadData. A Reader is safe for concurrent use by multiple
+// goroutines if the underlying io.ReaderAt is.
+//
+// Examples:
+//	data, err := ioutil.ReadFile("hello.mo") // or a mmapped file
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	r, err := mo.NewReaderBytes(data)
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	if msg, ok := r.Lookup("main.main", "Hello, world!"); ok {
+//		fmt.Println(msg.MsgStr)
+//	}
+type Reader struct {
+	r    io.ReaderAt
+	data []byte // the file data, nil for io.ReaderAt
+	size int64
+	bo   binary.ByteOrder
+
+	count     int      // number of strings, include the header
+	idOffset  uint32   // offset of table with original strings
+	strOffset uint32   // offset of table with translation strings
+	hashTable []uint32 // the hashing table, nil if missing
+
+	header Header
+	enc    *charset.Encoding // the charset of the strings
+}
+
+// NewReader returns a Reader reading MO file data from r,
+// which has the given size.
+func NewReader(r io.ReaderAt, size int64) (*Reader, error) {
+	p := &Reader{r: r, size: size}
+	if err := p.init(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// NewReaderBytes returns a Reader reading MO file data from data.
+//
+// The Reader doesn't copy data, which must not be modified
+// while the Reader is in use.
+func NewReaderBytes(data []byte) (*Reader, error) {
+	p := &Reader{data: data, size: int64(len(data))}
+	if err := p.init(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+func (p *Reader) init() error {
+	if p.size < MoHeaderSize {
+		return &FormatError{Offset: p.size, Index: -1, Msg: "truncated header"}
+	}
+	hdr, err := p.readAt(0, MoHeaderSize)
+	if err != nil {
+		return err
+	}
+	switch binary.LittleEndian.Uint32(hdr) {
+	case MoMagicLittleEndian:
+		p.bo = binary.LittleEndian
+	case MoMagicBigEndian:
+		p.bo = binary.BigEndian
+	default:
+		return &FormatError{Offset: 0, Index: -1, Msg: "invalid magic number"}
+	}
+	if v := p.bo.Uint16(hdr[4:]); v != 0 && v != 1 {
+		return &FormatError{Offset: 4, Index: -1, Msg: "invalid major version number"}
+	}
+	if v := p.bo.Uint16(hdr[6:]); v != 0 && v != 1 {
+		return &FormatError{Offset: 6, Index: -1, Msg: "invalid minor version number"}
+	}
+	count := int64(p.bo.Uint32(hdr[8:]))
+	p.idOffset = p.bo.Uint32(hdr[12:])
+	p.strOffset = p.bo.Uint32(hdr[16:])
+	hashSize := int64(p.bo.Uint32(hdr[20:]))
+	hashOffset := int64(p.bo.Uint32(hdr[24:]))
+
+	// the tables must be in the file, and must not overlap
+	tables := []struct {
+		name     string
+		fieldOff int64 // offset of the header field
+		off, n   int64
+	}{
+		{"header", 0, 0, MoHeaderSize},
+		{"table of original strings", 12, int64(p.idOffset), count * 8},
+		{"table of translation strings", 16, int64(p.strOffset), count * 8},
+		{"hashing table", 24, hashOffset, hashSize * 4},
+	}
+	for i, a := range tables {
+		if a.off+a.n > p.size {
+			return &FormatError{Offset: a.fieldOff, Index: -1, Msg: "truncated " + a.name}
+		}
+		for _, b := range tables[:i] {
+			if a.n != 0 && b.n != 0 && a.off < b.off+b.n && b.off < a.off+a.n {
+				return &FormatError{
+					Offset: a.fieldOff,
+					Index:  -1,
+					Msg:    fmt.Sprintf("%s overlaps %s", a.name, b.name),
+				}
+			}
+		}
+	}
+	p.count = int(count)
+
+	// check the strings, so the lookups never fail
+	if err := p.checkStrings(p.idOffset); err != nil {
+		return err
+	}
+	if err := p.checkStrings(p.strOffset); err != nil {
+		return err
+	}
+
+	// GNU's gettext ignores the hashing table of size <= 2
+	if hashSize > 2 {
+		// a full table makes every failed lookup probe all the slots
+		if hashSize <= count {
+			return &FormatError{Offset: 20, Index: -1, Msg: "hashing table too small"}
+		}
+		data, err := p.readAt(uint32(hashOffset), uint32(hashSize*4))
+		if err != nil {
+			return err
+		}
+		p.hashTable = make([]uint32, hashSize)
+		for i := range p.hashTable {
+			p.hashTable[i] = p.bo.Uint32(data[i*4:])
+			if p.hashTable[i] > uint32(count) {
+				return &FormatError{
+					Offset: hashOffset + int64(i)*4,
+					Index:  -1,
+					Msg:    "invalid hashing table entry",
+				}
+			}
+		}
+	}
+
+	// the header is the first entry, whose msgid is empty
+	p.enc = charset.UTF8
+	if p.count > 0 {
+		msgId, err := p.original(0)
+		if err != nil {
+			return err
+		}
+		if len(msgId) == 0 {
+			msg, err := p.Message(0)
+			if err != nil {
+				return err
+			}
+			p.header.fromMessage(&msg)
+			if enc := p.header.encoding(); !enc.IsUTF8() {
+				p.enc = enc
+				if msg, err = p.Message(0); err != nil {
+					return err
+				}
+				p.header = Header{}
+				p.header.fromMessage(&msg)
+			}
+		}
+	}
+	return nil
+}
+
+// checkStrings checks the strings of the length & offset table at off.
+func (p *Reader) checkStrings(off uint32) error {
+	const chunk = 512 // entries per read, caps the allocation of io.ReaderAt
+	for i := 0; i < p.count; i += chunk {
+		n := p.count - i
+		if n > chunk {
+			n = chunk
+		}
+		data, err := p.readAt(off+uint32(i*8), uint32(n*8))
+		if err != nil {
+			return err
+		}
+		for j := 0; j < n; j++ {
+			size, addr := int64(p.bo.Uint32(data[j*8:])), int64(p.bo.Uint32(data[j*8+4:]))
+			if addr+size > p.size {
+				return &FormatError{
+					Offset: int64(off) + int64(i+j)*8,
+					Index:  i + j,
+					Msg:    "truncated string",
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// Len returns the number of entries, include the header entry.
+func (p *Reader) Len() int {
+	return p.count
+}
+
+// Header returns the header of the MO file.
+func (p *Reader) Header() Header {
+	return p.header
+}
+
+// Message decodes the i-th entry of the MO file.
+//
+// The entries are sorted by the original strings, and the 0th
+// entry is the header if the file has one.
+func (p *Reader) Message(i int) (Message, error) {
+	if i < 0 || i >= p.count {
+		return Message{}, fmt.Errorf("gettext: invalid entry index %d", i)
+	}
+	msgId, err := p.original(i)
+	if err != nil {
+		return Message{}, withIndex(err, i)
+	}
+	msgStr, err := p.translation(i)
+	if err != nil {
+		return Message{}, withIndex(err, i)
+	}
+	return decodeMessage(p.enc.DecodeString(string(msgId)), p.enc.DecodeString(string(msgStr))), nil
+}
+
+// Lookup finds the message with the msgctxt and msgid.
+//
+// It uses the hashing table if the file has one, otherwise a binary
+// search over the sorted original strings. The header entry is not
+// a message, and can't be found by Lookup.
+func (p *Reader) Lookup(msgctxt, msgid string) (msg Message, ok bool) {
+	key := msgid
+	if msgctxt != "" {
+		key = msgctxt + EotSeparator + msgid
+	}
+	if key == "" {
+		return Message{}, false
+	}
+	key, err := p.enc.EncodeString(key)
+	if err != nil {
+		return Message{}, false // the charset doesn't have the key
+	}
+	cmp := func(i int) int {
+		s, err := p.original(i)
+		if err != nil {
+			return -1
+		}
+		return compareKey(s, key)
+	}
+
+	var idx int
+	if p.hashTable != nil {
+		idx = hashLookup(p.hashTable, p.count, key, cmp)
+	} else {
+		idx = binaryLookup(p.count, cmp)
+	}
+	if idx < 0 {
+		return Message{}, false
+	}
+	if msg, err = p.Message(idx); err != nil {
+		return Message{}, false
+	}
+	return msg, true
+}
+
+// original returns the i-th original string.
+func (p *Reader) original(i int) ([]byte, error) {
+	return p.readString(p.idOffset + uint32(i)*8)
+}
+
+// translation returns the i-th translation string.
+func (p *Reader) translation(i int) ([]byte, error) {
+	return p.readString(p.strOffset + uint32(i)*8)
+}
+
+// readString reads the string of the length & offset pair at off.
+func (p *Reader) readString(off uint32) ([]byte, error) {
+	pos, err := p.readAt(off, 8)
+	if err != nil {
+		return nil, err
+	}
+	return p.readAt(p.bo.Uint32(pos[4:]), p.bo.Uint32(pos))
+}
+
+func (p *Reader) readAt(off, n uint32) ([]byte, error) {
+	if int64(off)+int64(n) > p.size {
+		return nil, &FormatError{Offset: int64(off), Index: -1, Msg: "unexpected EOF"}
+	}
+	if p.data != nil {
+		return p.data[off : off+n : off+n], nil
+	}
+	buf := make([]byte, n)
+	// ReadAt may return io.EOF with a full buffer at the end of input
+	if m, err := p.r.ReadAt(buf, int64(off)); m < len(buf) {
+		if err != nil && err != io.EOF {
+			return nil, fmt.Errorf("gettext: %v", err)
+		}
+		return nil, &FormatError{Offset: int64(off) + int64(m), Index: -1, Msg: "unexpected EOF"}
+	}
+	return buf, nil
+}
+
+// compareKey compares the original string s, without msgid_plural,
+// with the key, like strcmp.
+func compareKey(s []byte, key string) int {
+	for i := 0; ; i++ {
+		var a, b byte
+		if i < len(s) {
+			a = s[i]
+		}
+		if i < len(key) {
+			b = key[i]
+		}
+		switch {
+		case a < b:
+			return -1
+		case a > b:
+			return +1
+		case a == 0:
+			return 0
+		}
+	}
+}
+
+// decodeMessage decodes the original and translation strings.
+func decodeMessage(msgId, msgStr string) Message {
+	var msg = Message{
+		MsgId:  msgId,
+		MsgStr: msgStr,
+	}
+	// Is this a context message?
+	if idx := strings.Index(msg.MsgId, EotSeparator); idx != -1 {
+		msg.MsgContext, msg.MsgId = msg.MsgId[:idx], msg.MsgId[idx+1:]
+	}
+	// Is this a plural message?
+	if idx := strings.Index(msg.MsgId, NulSeparator); idx != -1 {
+		msg.MsgId, msg.MsgIdPlural = msg.MsgId[:idx], msg.MsgId[idx+1:]
+		msg.MsgStrPlural = strings.Split(msg.MsgStr, NulSeparator)
+		msg.MsgStr = ""
+	}
+	return msg
+}
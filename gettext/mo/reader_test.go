@@ -0,0 +1,229 @@
+// Copyright 2013 ChaiShushan <chaishushan{AT}gmail.com>. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package mo
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+const testBigMoFile = "../../testdata/mm-viet.comp.mo"
+
+func TestReader(t *testing.T) {
+	names, err := filepath.Glob("../../testdata/*.mo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range names {
+		data, err := ioutil.ReadFile(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		f, err := LoadData(data)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		for _, r := range []*Reader{
+			testNewReader(t, data, false),
+			testNewReader(t, data, true),
+		} {
+			if !reflect.DeepEqual(r.Header(), f.MimeHeader) {
+				t.Fatalf("%s: expect = %v, got = %v", name, f.MimeHeader, r.Header())
+			}
+			if a, b := r.Len(), int(f.MsgIdCount); a != b {
+				t.Fatalf("%s: expect = %d, got = %d", name, b, a)
+			}
+			for _, v := range f.Messages {
+				msg, ok := r.Lookup(v.MsgContext, v.MsgId)
+				if !ok || !reflect.DeepEqual(&msg, &v) {
+					t.Fatalf("%s: expect = %v, got = %v", name, v, msg)
+				}
+			}
+			if _, ok := r.Lookup("", ""); ok {
+				t.Fatalf("%s: expect header not found", name)
+			}
+			if _, ok := r.Lookup("", "\xff not found"); ok {
+				t.Fatalf("%s: expect not found", name)
+			}
+		}
+	}
+}
+
+func TestReader_charset(t *testing.T) {
+	data, err := ioutil.ReadFile("../../testdata/mm-ko.euc-kr.mo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f, err := LoadData(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	utf8File, err := LoadData(f.EncodeData(&EncodeOptions{Charset: "UTF-8"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := utf8File.MimeHeader.Charset(); s != "UTF-8" {
+		t.Fatalf("expect = %q, got = %q", "UTF-8", s)
+	}
+	if !bytes.Equal(f.Data(), data) {
+		t.Fatalf("the data is not encoded to EUC-KR")
+	}
+	for i, v := range []func(msgctxt, msgid string) (Message, bool){
+		f.Lookup,
+		utf8File.Lookup,
+		testNewReader(t, data, false).Lookup,
+		testNewReader(t, data, true).Lookup,
+	} {
+		if msg, ok := v("", "OUTPUT"); !ok || msg.MsgStr != "출력" {
+			t.Fatalf("%d: expect = %q, got = %q", i, "출력", msg.MsgStr)
+		}
+		if _, ok := v("", "☃"); ok {
+			t.Fatalf("%d: expect not found", i)
+		}
+	}
+}
+
+func TestReader_NoHashTable(t *testing.T) {
+	r, err := NewReaderBytes(testMoFile.EncodeData(&EncodeOptions{NoHashTable: true}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.hashTable != nil {
+		t.Fatalf("bad hash table: %v", r.hashTable)
+	}
+	for _, v := range testMoFile.Messages {
+		msg, ok := r.Lookup(v.MsgContext, v.MsgId)
+		if !ok || !reflect.DeepEqual(&msg, &v) {
+			t.Fatalf("expect = %v, got = %v", v, msg)
+		}
+	}
+	if _, err := r.Message(r.Len()); err == nil {
+		t.Fatal("expect error")
+	}
+}
+
+func TestReader_FormatError(t *testing.T) {
+	data := testMoFile.Data()
+	le := func(b []byte, off int, v uint32) []byte {
+		b = append([]byte(nil), b...)
+		binary.LittleEndian.PutUint32(b[off:], v)
+		return b
+	}
+	for i, v := range []struct {
+		data   []byte
+		offset int64
+		index  int
+	}{
+		{data[:10], 10, -1},
+		{le(data, 0, 0x12345678), 0, -1},
+		{le(data, 8, 0xffffffff), 12, -1},
+		{le(data, 16, MoHeaderSize), 16, -1},
+		{le(data, 24, 0), 24, -1},
+		{le(data, MoHeaderSize+8+4, 0xffffff00), MoHeaderSize + 8, 1},
+		{le(data, int(binary.LittleEndian.Uint32(data[24:])), 100), int64(binary.LittleEndian.Uint32(data[24:])), -1},
+		{data[:len(data)-5], int64(binary.LittleEndian.Uint32(data[16:])) + 8*4, 4},
+	} {
+		for _, readerAt := range []bool{false, true} {
+			var err error
+			if readerAt {
+				_, err = NewReader(bytes.NewReader(v.data), int64(len(v.data)))
+			} else {
+				_, err = LoadData(v.data)
+			}
+			e, ok := err.(*FormatError)
+			if !ok {
+				t.Fatalf("%d: expect *FormatError, got = %v", i, err)
+			}
+			if e.Offset != v.offset || e.Index != v.index {
+				t.Fatalf("%d: expect = %d/%d, got = %v", i, v.offset, v.index, e)
+			}
+		}
+	}
+}
+
+func testNewReader(t *testing.T, data []byte, readerAt bool) *Reader {
+	var (
+		r   *Reader
+		err error
+	)
+	if readerAt {
+		r, err = NewReader(bytes.NewReader(data), int64(len(data)))
+	} else {
+		r, err = NewReaderBytes(data)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	return r
+}
+
+func BenchmarkLoadData(b *testing.B) {
+	data := benchmarkData(b)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := LoadData(data); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkNewReaderBytes(b *testing.B) {
+	data := benchmarkData(b)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := NewReaderBytes(data); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkFile_Lookup(b *testing.B) {
+	f, err := LoadData(benchmarkData(b))
+	if err != nil {
+		b.Fatal(err)
+	}
+	msg := f.Messages[len(f.Messages)/2]
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, ok := f.Lookup(msg.MsgContext, msg.MsgId); !ok {
+			b.Fatal("not found")
+		}
+	}
+}
+
+func BenchmarkReader_Lookup(b *testing.B) {
+	data := benchmarkData(b)
+	f, err := LoadData(data)
+	if err != nil {
+		b.Fatal(err)
+	}
+	r, err := NewReaderBytes(data)
+	if err != nil {
+		b.Fatal(err)
+	}
+	msg := f.Messages[len(f.Messages)/2]
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, ok := r.Lookup(msg.MsgContext, msg.MsgId); !ok {
+			b.Fatal("not found")
+		}
+	}
+}
+
+func benchmarkData(b *testing.B) []byte {
+	data, err := ioutil.ReadFile(testBigMoFile)
+	if err != nil {
+		b.Fatal(err)
+	}
+	return data
+}
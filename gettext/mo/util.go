@@ -6,6 +6,7 @@ package mo
 
 import (
 	"bytes"
+	"fmt"
 	"strings"
 )
 
@@ -45,19 +46,18 @@ func decodePoString(text string) string {
 	return strings.Join(lines, "")
 }
 
+// encodePoString returns the quoted lines of the po string,
+// the lines are split after the "\n". The non-UTF-8 bytes are kept.
 func encodePoString(text string) string {
 	var buf bytes.Buffer
-	lines := strings.Split(text, "\n")
+	lines := strings.SplitAfter(text, "\n")
+	if len(lines) > 1 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
 	for i := 0; i < len(lines); i++ {
-		if lines[i] == "" {
-			if i != len(lines)-1 {
-				buf.WriteString(`"\n"` + "\n")
-			}
-			continue
-		}
 		buf.WriteRune('"')
-		for _, r := range lines[i] {
-			switch r {
+		for j := 0; j < len(lines[i]); j++ {
+			switch c := lines[i][j]; c {
 			case '\\':
 				buf.WriteString(`\\`)
 			case '"':
@@ -67,44 +67,21 @@ func encodePoString(text string) string {
 			case '\t':
 				buf.WriteString(`\t`)
 			default:
-				buf.WriteRune(r)
+				buf.WriteByte(c) // keep the bytes of other charsets
 			}
 		}
-		buf.WriteString(`\n"` + "\n")
+		buf.WriteString(`"` + "\n")
 	}
 	return buf.String()
 }
 
-func encodeCommentPoString(text string) string {
-	var buf bytes.Buffer
-	lines := strings.Split(text, "\n")
-	if len(lines) > 1 {
-		buf.WriteString(`""` + "\n")
-	}
-	for i := 0; i < len(lines); i++ {
-		if len(lines) > 0 {
-			buf.WriteString("#| ")
-		}
-		buf.WriteRune('"')
-		for _, r := range lines[i] {
-			switch r {
-			case '\\':
-				buf.WriteString(`\\`)
-			case '"':
-				buf.WriteString(`\"`)
-			case '\n':
-				buf.WriteString(`\n`)
-			case '\t':
-				buf.WriteString(`\t`)
-			default:
-				buf.WriteRune(r)
-			}
-		}
-		if i < len(lines)-1 {
-			buf.WriteString(`\n"` + "\n")
-		} else {
-			buf.WriteString(`"`)
-		}
+// writePoString writes the keyword and the quoted po string, the multi-line
+// string starts with an empty line like GNU's msgunfmt.
+func writePoString(buf *bytes.Buffer, keyword, text string) {
+	s := encodePoString(text)
+	if strings.Count(s, "\n") > 1 {
+		fmt.Fprintf(buf, "%s \"\"\n%s", keyword, s)
+	} else {
+		fmt.Fprintf(buf, "%s %s", keyword, s)
 	}
-	return buf.String()
 }
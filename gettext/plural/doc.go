@@ -31,6 +31,16 @@ Examples:
 		// ??: 8
 	}
 
+Any Plural-Forms header can be compiled:
+
+	forms, err := plural.ParseForms("nplurals=3; plural=n==1 ? 0 : n==2 ? 1 : 2;")
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("%d: %d\n", forms.NPlurals, forms.Index(2))
+	// Output:
+	// 3: 1
+
 See http://www.gnu.org/software/gettext/manual/html_node/Plural-forms.html
 */
 package plural
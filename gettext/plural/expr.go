@@ -0,0 +1,419 @@
+// Copyright 2013 ChaiShushan <chaishushan{AT}gmail.com>. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package plural
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// SyntaxError records an error and the position in a plural expression.
+type SyntaxError struct {
+	Expr   string // the plural expression
+	Offset int    // byte offset in Expr
+	Msg    string // description of error
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("plural: %s at offset %d in %q", e.Msg, e.Offset, e.Expr)
+}
+
+// Forms is a compiled Plural-Forms header.
+//
+// See http://www.gnu.org/software/gettext/manual/html_node/Plural-forms.html
+type Forms struct {
+	NPlurals int    // nplurals=N
+	Plural   string // plural=EXPRESSION
+	expr     node
+}
+
+// ParseForms parses the value of a Plural-Forms header.
+//
+// Examples:
+//	forms, err := plural.ParseForms("nplurals=2; plural=(n != 1);")
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	fmt.Println(forms.NPlurals, forms.Index(1), forms.Index(2))
+//	// Output: 2 0 1
+func ParseForms(s string) (*Forms, error) {
+	var (
+		nplurals string
+		expr     string
+		hasN     bool
+		hasP     bool
+	)
+	for _, field := range strings.Split(s, ";") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		idx := strings.Index(field, "=")
+		if idx < 0 {
+			return nil, fmt.Errorf("plural: invalid field %q in %q", field, s)
+		}
+		switch key, val := strings.TrimSpace(field[:idx]), field[idx+1:]; key {
+		case "nplurals":
+			nplurals, hasN = strings.TrimSpace(val), true
+		case "plural":
+			expr, hasP = val, true
+		default:
+			return nil, fmt.Errorf("plural: unknown field %q in %q", key, s)
+		}
+	}
+	if !hasN {
+		return nil, fmt.Errorf("plural: missing nplurals in %q", s)
+	}
+	if !hasP {
+		return nil, fmt.Errorf("plural: missing plural in %q", s)
+	}
+	n, err := strconv.Atoi(nplurals)
+	if err != nil || n < 1 {
+		return nil, fmt.Errorf("plural: invalid nplurals %q in %q", nplurals, s)
+	}
+	x, err := parseExpr(expr)
+	if err != nil {
+		return nil, err
+	}
+	return &Forms{
+		NPlurals: n,
+		Plural:   strings.TrimSpace(expr),
+		expr:     x,
+	}, nil
+}
+
+// Index returns the plural form index of n.
+//
+// Like GNU's gettext, n is converted to unsigned long and
+// an out of range result selects the form 0.
+func (f *Forms) Index(n int) int {
+	v, ok := f.expr.eval(uint64(n))
+	if !ok || v >= uint64(f.NPlurals) {
+		return 0
+	}
+	return int(v)
+}
+
+// Compile compiles a plural expression to a formula.
+//
+// The expression is a C expression of n, like "(n != 1)".
+// The formula returns 0 if the expression divides by zero.
+func Compile(expr string) (func(n int) int, error) {
+	x, err := parseExpr(expr)
+	if err != nil {
+		return nil, err
+	}
+	return func(n int) int {
+		v, ok := x.eval(uint64(n))
+		if !ok {
+			return 0
+		}
+		return int(v)
+	}, nil
+}
+
+// node is a node of the plural expression tree.
+//
+// All values are unsigned long, as GNU's gettext.
+type node interface {
+	eval(n uint64) (v uint64, ok bool)
+}
+
+type varNode struct{}
+
+type numNode struct {
+	val uint64
+}
+
+type notNode struct {
+	x node
+}
+
+type binaryNode struct {
+	op   string
+	x, y node
+}
+
+type condNode struct {
+	cond, x, y node
+}
+
+func (varNode) eval(n uint64) (uint64, bool) {
+	return n, true
+}
+
+func (p numNode) eval(n uint64) (uint64, bool) {
+	return p.val, true
+}
+
+func (p notNode) eval(n uint64) (uint64, bool) {
+	v, ok := p.x.eval(n)
+	return boolValue(v == 0), ok
+}
+
+func (p binaryNode) eval(n uint64) (uint64, bool) {
+	x, ok := p.x.eval(n)
+	if !ok {
+		return 0, false
+	}
+	// short circuit
+	switch {
+	case p.op == "&&" && x == 0:
+		return 0, true
+	case p.op == "||" && x != 0:
+		return 1, true
+	}
+	y, ok := p.y.eval(n)
+	if !ok {
+		return 0, false
+	}
+	switch p.op {
+	case "&&", "||":
+		return boolValue(y != 0), true
+	case "==":
+		return boolValue(x == y), true
+	case "!=":
+		return boolValue(x != y), true
+	case "<":
+		return boolValue(x < y), true
+	case ">":
+		return boolValue(x > y), true
+	case "<=":
+		return boolValue(x <= y), true
+	case ">=":
+		return boolValue(x >= y), true
+	case "+":
+		return x + y, true
+	case "-":
+		return x - y, true
+	case "*":
+		return x * y, true
+	case "/":
+		if y == 0 {
+			return 0, false
+		}
+		return x / y, true
+	case "%":
+		if y == 0 {
+			return 0, false
+		}
+		return x % y, true
+	}
+	return 0, false
+}
+
+func (p condNode) eval(n uint64) (uint64, bool) {
+	v, ok := p.cond.eval(n)
+	if !ok {
+		return 0, false
+	}
+	if v != 0 {
+		return p.x.eval(n)
+	}
+	return p.y.eval(n)
+}
+
+func boolValue(b bool) uint64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+// binaryOps are the binary operators, from the lowest precedence.
+var binaryOps = [][]string{
+	{"||"},
+	{"&&"},
+	{"==", "!="},
+	{"<=", ">=", "<", ">"},
+	{"+", "-"},
+	{"*", "/", "%"},
+}
+
+// exprParser is a recursive descent parser of the plural expression:
+//
+//	expr    = or [ "?" expr ":" expr ]
+//	or      = and { "||" and }
+//	and     = eq { "&&" eq }
+//	eq      = rel { ( "==" | "!=" ) rel }
+//	rel     = add { ( "<" | ">" | "<=" | ">=" ) add }
+//	add     = mul { ( "+" | "-" ) mul }
+//	mul     = unary { ( "*" | "/" | "%" ) unary }
+//	unary   = "!" unary | primary
+//	primary = "n" | number | "(" expr ")"
+type exprParser struct {
+	src   string
+	pos   int
+	depth int // the nesting depth of "(", "!" and "?:"
+}
+
+// maxExprDepth is the max nesting depth of the expression, so the
+// recursion of the parser is bounded.
+const maxExprDepth = 100
+
+func parseExpr(s string) (node, error) {
+	p := &exprParser{src: s}
+	x, err := p.parseCond()
+	if err != nil {
+		return nil, err
+	}
+	if p.skipSpace(); p.pos < len(p.src) {
+		return nil, p.errorf("unexpected %q", p.src[p.pos:p.pos+1])
+	}
+	return x, nil
+}
+
+func (p *exprParser) errorf(format string, args ...interface{}) error {
+	return &SyntaxError{
+		Expr:   p.src,
+		Offset: p.pos,
+		Msg:    fmt.Sprintf(format, args...),
+	}
+}
+
+func (p *exprParser) skipSpace() {
+	for p.pos < len(p.src) {
+		switch p.src[p.pos] {
+		case ' ', '\t', '\n', '\r', '\f', '\v':
+			p.pos++
+		default:
+			return
+		}
+	}
+}
+
+// accept consumes the token if the next token is tok.
+func (p *exprParser) accept(tok string) bool {
+	p.skipSpace()
+	if !strings.HasPrefix(p.src[p.pos:], tok) {
+		return false
+	}
+	// don't take "<" of "<=", or "!" of "!="
+	if len(tok) == 1 && p.pos+1 < len(p.src) && p.src[p.pos+1] == '=' {
+		switch tok {
+		case "<", ">", "!", "=":
+			return false
+		}
+	}
+	p.pos += len(tok)
+	return true
+}
+
+// enter increases the nesting depth, or returns an error if the
+// expression is nested too deeply.
+func (p *exprParser) enter() error {
+	if p.depth >= maxExprDepth {
+		return p.errorf("expression nested too deeply")
+	}
+	p.depth++
+	return nil
+}
+
+func (p *exprParser) leave() {
+	p.depth--
+}
+
+func (p *exprParser) parseCond() (node, error) {
+	if err := p.enter(); err != nil {
+		return nil, err
+	}
+	defer p.leave()
+
+	cond, err := p.parseBinary(0)
+	if err != nil {
+		return nil, err
+	}
+	if !p.accept("?") {
+		return cond, nil
+	}
+	x, err := p.parseCond()
+	if err != nil {
+		return nil, err
+	}
+	if !p.accept(":") {
+		return nil, p.errorf("missing ':' in conditional expression")
+	}
+	y, err := p.parseCond()
+	if err != nil {
+		return nil, err
+	}
+	return condNode{cond: cond, x: x, y: y}, nil
+}
+
+func (p *exprParser) parseBinary(level int) (node, error) {
+	if level >= len(binaryOps) {
+		return p.parseUnary()
+	}
+	x, err := p.parseBinary(level + 1)
+	if err != nil {
+		return nil, err
+	}
+loop:
+	for {
+		for _, op := range binaryOps[level] {
+			if p.accept(op) {
+				y, err := p.parseBinary(level + 1)
+				if err != nil {
+					return nil, err
+				}
+				x = binaryNode{op: op, x: x, y: y}
+				continue loop
+			}
+		}
+		return x, nil
+	}
+}
+
+func (p *exprParser) parseUnary() (node, error) {
+	if p.accept("!") {
+		if err := p.enter(); err != nil {
+			return nil, err
+		}
+		defer p.leave()
+		x, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return notNode{x: x}, nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *exprParser) parsePrimary() (node, error) {
+	p.skipSpace()
+	if p.pos >= len(p.src) {
+		return nil, p.errorf("unexpected end of expression")
+	}
+	switch c := p.src[p.pos]; {
+	case c == 'n':
+		p.pos++
+		return varNode{}, nil
+	case c >= '0' && c <= '9':
+		start := p.pos
+		for p.pos < len(p.src) && p.src[p.pos] >= '0' && p.src[p.pos] <= '9' {
+			p.pos++
+		}
+		v, err := strconv.ParseUint(p.src[start:p.pos], 10, 64)
+		if err != nil {
+			s := p.src[start:p.pos]
+			p.pos = start
+			return nil, p.errorf("invalid number %q", s)
+		}
+		return numNode{val: v}, nil
+	case c == '(':
+		p.pos++
+		x, err := p.parseCond()
+		if err != nil {
+			return nil, err
+		}
+		if !p.accept(")") {
+			return nil, p.errorf("missing ')'")
+		}
+		return x, nil
+	default:
+		return nil, p.errorf("unexpected %q", p.src[p.pos:p.pos+1])
+	}
+}
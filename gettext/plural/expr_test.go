@@ -0,0 +1,165 @@
+// Copyright 2013 ChaiShushan <chaishushan{AT}gmail.com>. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package plural
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseForms_Table(t *testing.T) {
+	for i, v := range FormsTable {
+		forms, err := ParseForms(v.Value)
+		if err != nil {
+			t.Fatalf("%d/%s: %v", i, v.Lang, err)
+		}
+		// the hard-coded "(n != 1)" formula returns 0 for n == 0
+		formula := formulaTable[fmtForms(v.Value)]
+		for n := 1; n < 1000; n++ {
+			if a, b := forms.Index(n), formula(n); a != b {
+				t.Fatalf("%d/%s: n = %d, expect = %d, got = %d", i, v.Lang, n, b, a)
+			}
+		}
+	}
+}
+
+func TestParseForms(t *testing.T) {
+	for i, v := range testForms {
+		forms, err := ParseForms(v.forms)
+		if err != nil {
+			t.Fatalf("%d: %v", i, err)
+		}
+		if forms.NPlurals != v.nplurals {
+			t.Fatalf("%d: expect = %d, got = %d", i, v.nplurals, forms.NPlurals)
+		}
+		for j := 0; j < len(v.in); j++ {
+			if out := forms.Index(v.in[j]); out != v.out[j] {
+				t.Fatalf("%d: n = %d, expect = %d, got = %d", i, v.in[j], v.out[j], out)
+			}
+		}
+	}
+}
+
+func TestParseForms_Error(t *testing.T) {
+	for i, s := range testBadForms {
+		if _, err := ParseForms(s); err == nil {
+			t.Fatalf("%d: %q: expect error", i, s)
+		}
+	}
+}
+
+func TestCompile(t *testing.T) {
+	for i, v := range testExprs {
+		f, err := Compile(v.expr)
+		if err != nil {
+			t.Fatalf("%d: %v", i, err)
+		}
+		if out := f(v.n); out != v.out {
+			t.Fatalf("%d: %s, n = %d, expect = %d, got = %d", i, v.expr, v.n, v.out, out)
+		}
+	}
+	_, err := Compile("n % 10 == 1 ?")
+	if e, ok := err.(*SyntaxError); !ok || e.Offset != len("n % 10 == 1 ?") {
+		t.Fatalf("expect SyntaxError at end, got = %v", err)
+	}
+}
+
+func TestCompile_depth(t *testing.T) {
+	for _, v := range []struct {
+		open, close string
+	}{
+		{"(", ")"},
+		{"!", ""},
+		{"n ? 1 : ", ""},
+	} {
+		n := maxExprDepth - 1
+		expr := strings.Repeat(v.open, n) + "n" + strings.Repeat(v.close, n)
+		if _, err := Compile(expr); err != nil {
+			t.Fatalf("%q: depth %d: %v", v.open, n, err)
+		}
+		n = 100 * maxExprDepth
+		expr = strings.Repeat(v.open, n) + "n" + strings.Repeat(v.close, n)
+		if _, err := Compile(expr); err == nil || !strings.Contains(err.Error(), "nested too deeply") {
+			t.Fatalf("%q: depth %d: expect error, got = %v", v.open, n, err)
+		}
+	}
+}
+
+var testForms = []struct {
+	forms    string
+	nplurals int
+	in       []int
+	out      []int
+}{
+	{"nplurals=1; plural=0", 1, []int{0, 1, 2}, []int{0, 0, 0}},
+	{" nplurals = 2 ; plural = n != 1 ; ", 2, []int{0, 1, 2}, []int{1, 0, 1}},
+	{"nplurals=2; plural=n>1;", 2, []int{0, 1, 2}, []int{0, 0, 1}},
+
+	// Arabic
+	{
+		"nplurals=6; plural=n==0 ? 0 : n==1 ? 1 : n==2 ? 2 : n%100>=3 && n%100<=10 ? 3 : n%100>=11 ? 4 : 5;",
+		6,
+		[]int{0, 1, 2, 3, 10, 11, 99, 100, 101, 102, 103, 111},
+		[]int{0, 1, 2, 3, 3, 4, 4, 5, 5, 5, 3, 4},
+	},
+
+	// Welsh
+	{
+		"nplurals=4; plural=(n==1) ? 0 : (n==2) ? 1 : (n != 8 && n != 11) ? 2 : 3;",
+		4,
+		[]int{1, 2, 3, 8, 11},
+		[]int{0, 1, 2, 3, 3},
+	},
+
+	// out of range: form 0
+	{"nplurals=2; plural=n;", 2, []int{0, 1, 2, 3}, []int{0, 1, 0, 0}},
+
+	// unsigned long: -1 != 1
+	{"nplurals=2; plural=(n != 1);", 2, []int{-1}, []int{1}},
+}
+
+var testBadForms = []string{
+	"",
+	"nplurals=2;",
+	"plural=(n != 1);",
+	"nplurals=INTEGER; plural=EXPRESSION;",
+	"nplurals=0; plural=0;",
+	"nplurals=2; plural=(n != 1;",
+	"nplurals=2; plural=n ? 1;",
+	"nplurals=2; plural=n = 1;",
+	"nplurals=2; plural=-n;",
+	"nplurals=2; plural=x;",
+	"nplurals=2; plural=;",
+	"nplurals=2; plurals=n;",
+}
+
+var testExprs = []struct {
+	expr string
+	n    int
+	out  int
+}{
+	{"n", 7, 7},
+	{"n+1", 7, 8},
+	{"n-1", 7, 6},
+	{"n*2+1", 7, 15},
+	{"1+n*2", 7, 15},
+	{"(1+n)*2", 7, 16},
+	{"n/2", 7, 3},
+	{"n%3", 7, 1},
+	{"n/0", 7, 0},
+	{"n%0", 7, 0},
+	{"!n", 0, 1},
+	{"!!n", 5, 1},
+	{"n<=7", 7, 1},
+	{"n<7", 7, 0},
+	{"n>=7", 7, 1},
+	{"n>7", 7, 0},
+	{"n==7||n/0", 7, 1},
+	{"n!=7&&n/0", 7, 0},
+	{"n ? 1 : 2", 0, 2},
+	{"n==1 ? 0 : n==2 ? 1 : 2", 2, 1},
+	{"n==1 ? 0 : n==2 ? 1 : 2", 3, 2},
+	{"10-n", 11, -1},
+}
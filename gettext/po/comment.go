@@ -6,7 +6,6 @@ package po
 
 import (
 	"bytes"
-	"fmt"
 	"io"
 	"strconv"
 	"strings"
@@ -22,6 +21,7 @@ type Comment struct {
 	Flags             []string // #, fuzzy,c-format,range:0..10
 	PrevMsgContext    string   // #| msgctxt previous-context
 	PrevMsgId         string   // #| msgid previous-untranslated-string
+	PrevMsgIdPlural   string   // #| msgid_plural previous-untranslated-string-plural
 }
 
 func (p *Comment) less(q *Comment) bool {
@@ -47,177 +47,124 @@ func (p *Comment) readPoComment(r *lineReader) (err error) {
 	if err = r.skipBlankLine(); err != nil {
 		return err
 	}
-	defer func(oldPos int) {
-		newPos := r.currentPos()
-		if newPos != oldPos && err == io.EOF {
+	defer func() {
+		if err == io.EOF {
 			err = nil
 		}
-	}(r.currentPos())
+	}()
 
 	p.StartLine = r.currentPos() + 1
+
+	// the empty comment lines are kept as the empty lines of the text,
+	// so the lines are joined by whether a line has been read
+	var translator, extracted bool
 	for {
 		var s string
 		if s, _, err = r.currentLine(); err != nil {
 			return
 		}
+		// the blank lines between comments are ignored
+		if reBlankLine.MatchString(s) {
+			if err = r.skipBlankLine(); err != nil {
+				return
+			}
+			continue
+		}
 		if len(s) == 0 || s[0] != '#' {
 			return
 		}
 
-		if err = p.readTranslatorComment(r); err != nil {
-			return
-		}
-		if err = p.readExtractedComment(r); err != nil {
-			return
-		}
-		if err = p.readReferenceComment(r); err != nil {
-			return
-		}
-		if err = p.readFlagsComment(r); err != nil {
-			return
-		}
-		if err = p.readPrevMsgContext(r); err != nil {
-			return
-		}
-		if err = p.readPrevMsgId(r); err != nil {
-			return
+		switch {
+		case strings.HasPrefix(s, "#."):
+			p.readExtractedComment(r, extracted)
+			extracted = true
+		case strings.HasPrefix(s, "#:"):
+			p.readReferenceComment(r)
+		case strings.HasPrefix(s, "#,"):
+			p.readFlagsComment(r)
+		case strings.HasPrefix(s, "#|"):
+			if err = p.readPrevComment(r, "#|"); err != nil {
+				return
+			}
+		case strings.HasPrefix(s, "#~|"):
+			if err = p.readPrevComment(r, "#~|"); err != nil {
+				return
+			}
+		case strings.HasPrefix(s, "#~"):
+			return // the obsolete message
+		default:
+			p.readTranslatorComment(r, translator)
+			translator = true
 		}
 	}
-
-	return nil
 }
 
-func (p *Comment) readTranslatorComment(r *lineReader) (err error) {
-	const prefix = "# " // .,:|
-	for {
-		var s string
-		if s, _, err = r.readLine(); err != nil {
-			return err
-		}
-		if len(s) < 1 || s[0] != '#' {
-			r.unreadLine()
-			return nil
-		}
-		if len(s) >= 2 {
-			switch s[1] {
-			case '.', ',', ':', '|':
-				r.unreadLine()
-				return nil
-			}
-		}
-		if p.TranslatorComment != "" {
-			p.TranslatorComment += "\n"
-		}
-		p.TranslatorComment += strings.TrimSpace(s[1:])
+func (p *Comment) readTranslatorComment(r *lineReader, more bool) {
+	s, _, _ := r.readLine()
+	if more {
+		p.TranslatorComment += "\n"
 	}
-	return nil
+	p.TranslatorComment += strings.TrimSpace(s[1:])
 }
 
-func (p *Comment) readExtractedComment(r *lineReader) (err error) {
+func (p *Comment) readExtractedComment(r *lineReader, more bool) {
 	const prefix = "#."
-	for {
-		var s string
-		if s, _, err = r.readLine(); err != nil {
-			return err
-		}
-		if len(s) < len(prefix) || s[:len(prefix)] != prefix {
-			r.unreadLine()
-			return nil
-		}
-		if p.ExtractedComment != "" {
-			p.ExtractedComment += "\n"
-		}
-		p.ExtractedComment += strings.TrimSpace(s[len(prefix):])
+	s, _, _ := r.readLine()
+	if more {
+		p.ExtractedComment += "\n"
 	}
-	return nil
+	p.ExtractedComment += strings.TrimSpace(s[len(prefix):])
 }
 
-func (p *Comment) readReferenceComment(r *lineReader) (err error) {
+func (p *Comment) readReferenceComment(r *lineReader) {
 	const prefix = "#:"
-	for {
-		var s string
-		if s, _, err = r.readLine(); err != nil {
-			return err
-		}
-		if len(s) < len(prefix) || s[:len(prefix)] != prefix {
-			r.unreadLine()
-			return nil
-		}
-		ss := strings.Split(strings.TrimSpace(s[len(prefix):]), " ")
-		for i := 0; i < len(ss); i++ {
-			idx := strings.Index(ss[i], ":")
-			if idx <= 0 {
-				continue
-			}
-			name := strings.TrimSpace(ss[i][:idx])
-			line, _ := strconv.Atoi(strings.TrimSpace(ss[i][idx+1:]))
-			p.ReferenceFile = append(p.ReferenceFile, name)
-			p.ReferenceLine = append(p.ReferenceLine, line)
-		}
+	s, _, _ := r.readLine()
+	ss := strings.Fields(s[len(prefix):])
+	for i := 0; i < len(ss); i++ {
+		idx := strings.LastIndex(ss[i], ":")
+		if idx <= 0 {
+			continue
+		}
+		name := strings.TrimSpace(ss[i][:idx])
+		line, _ := strconv.Atoi(strings.TrimSpace(ss[i][idx+1:]))
+		p.ReferenceFile = append(p.ReferenceFile, name)
+		p.ReferenceLine = append(p.ReferenceLine, line)
 	}
-	return nil
 }
 
-func (p *Comment) readFlagsComment(r *lineReader) (err error) {
+func (p *Comment) readFlagsComment(r *lineReader) {
 	const prefix = "#,"
-	for {
-		var s string
-		if s, _, err = r.readLine(); err != nil {
-			return err
-		}
-		if len(s) < len(prefix) || s[:len(prefix)] != prefix {
-			r.unreadLine()
-			return nil
-		}
-		ss := strings.Split(strings.TrimSpace(s[len(prefix):]), ",")
-		for i := 0; i < len(ss); i++ {
-			p.Flags = append(p.Flags, strings.TrimSpace(ss[i]))
+	s, _, _ := r.readLine()
+	ss := strings.Split(strings.TrimSpace(s[len(prefix):]), ",")
+	for i := 0; i < len(ss); i++ {
+		if flag := strings.TrimSpace(ss[i]); flag != "" {
+			p.Flags = append(p.Flags, flag)
 		}
 	}
-	return nil
 }
 
-func (p *Comment) readPrevMsgContext(r *lineReader) (err error) {
-	var s string
-	if s, _, err = r.currentLine(); err != nil {
-		return
+// readPrevComment reads the "#| keyword" comment, the mark is "#|",
+// or "#~|" for the obsolete message.
+func (p *Comment) readPrevComment(r *lineReader, mark string) (err error) {
+	s, _, _ := r.currentLine()
+	t := strings.TrimLeft(s[len(mark):], " \t")
+	switch keyword, _, offset := splitKeyword(t); keyword {
+	case "msgctxt":
+		p.PrevMsgContext, err = p.readString(r, len(s)-len(t)+offset, mark)
+	case "msgid":
+		p.PrevMsgId, err = p.readString(r, len(s)-len(t)+offset, mark)
+	case "msgid_plural":
+		p.PrevMsgIdPlural, err = p.readString(r, len(s)-len(t)+offset, mark)
+	default:
+		r.readLine() // unknown previous field
 	}
-	if !rePrevMsgContextComments.MatchString(s) {
-		return
-	}
-	p.PrevMsgContext, err = p.readString(r)
-	return
-}
-
-func (p *Comment) readPrevMsgId(r *lineReader) (err error) {
-	var s string
-	if s, _, err = r.currentLine(); err != nil {
-		return
-	}
-	if !rePrevMsgIdComments.MatchString(s) {
-		return
-	}
-	p.PrevMsgId, err = p.readString(r)
 	return
 }
 
-func (p *Comment) readString(r *lineReader) (msg string, err error) {
-	var s string
-	if s, _, err = r.readLine(); err != nil {
-		return
-	}
-	msg += decodePoString(s)
-	for {
-		if s, _, err = r.readLine(); err != nil {
-			return
-		}
-		if !reStringLineComments.MatchString(s) {
-			r.unreadLine()
-			break
-		}
-		msg += decodePoString(s)
-	}
-	return
+// readString reads the string of the "#| keyword" line and the following
+// "#|" string lines, offset is the start of the string in the keyword line.
+func (p *Comment) readString(r *lineReader, offset int, mark string) (msg string, err error) {
+	return readPoString(r, offset, mark)
 }
 
 // GetFuzzy gets the fuzzy flag.
@@ -230,47 +177,44 @@ func (p *Comment) GetFuzzy() bool {
 	return false
 }
 
-// SetFuzzy sets the fuzzy flag.
+// SetFuzzy sets the fuzzy flag, the fuzzy flag is the first flag
+// like GNU's msgmerge.
 func (p *Comment) SetFuzzy(fuzzy bool) {
-	//
+	if p.GetFuzzy() == fuzzy {
+		return
+	}
+	if fuzzy {
+		p.Flags = append([]string{"fuzzy"}, p.Flags...)
+		return
+	}
+	var flags []string
+	for _, s := range p.Flags {
+		if s != "fuzzy" {
+			flags = append(flags, s)
+		}
+	}
+	p.Flags = flags
 }
 
 // String returns the po format comment string.
 func (p Comment) String() string {
 	var buf bytes.Buffer
-	if p.TranslatorComment != "" {
-		ss := strings.Split(p.TranslatorComment, "\n")
-		for i := 0; i < len(ss); i++ {
-			fmt.Fprintf(&buf, "# %s\n", ss[i])
-		}
-	}
-	if p.ExtractedComment != "" {
-		ss := strings.Split(p.ExtractedComment, "\n")
-		for i := 0; i < len(ss); i++ {
-			fmt.Fprintf(&buf, "#. %s\n", ss[i])
-		}
-	}
-	if a, b := len(p.ReferenceFile), len(p.ReferenceLine); a != 0 && a == b {
-		fmt.Fprintf(&buf, "#:")
-		for i := 0; i < len(p.ReferenceFile); i++ {
-			fmt.Fprintf(&buf, " %s:%d", p.ReferenceFile[i], p.ReferenceLine[i])
-		}
-		fmt.Fprintf(&buf, "\n")
-	}
-	if len(p.Flags) != 0 {
-		fmt.Fprintf(&buf, "#, %s", p.Flags[0])
-		for i := 1; i < len(p.Flags); i++ {
-			fmt.Fprintf(&buf, ", %s", p.Flags[i])
-		}
-		fmt.Fprintf(&buf, "\n")
-	}
-	if p.PrevMsgContext != "" {
-		s := encodeCommentPoString(p.PrevMsgContext)
-		fmt.Fprintf(&buf, "#| msgctxt %s\n", s)
-	}
-	if p.PrevMsgId != "" {
-		s := encodeCommentPoString(p.PrevMsgId)
-		fmt.Fprintf(&buf, "#| msgid %s\n", s)
-	}
+	newPoWriter(&buf, defaultWidth).writeComment(&p, false)
 	return buf.String()
 }
+
+// clone returns a deep copy of the comment.
+func (p *Comment) clone() Comment {
+	q := *p
+	q.ReferenceFile = cloneStrings(p.ReferenceFile)
+	q.ReferenceLine = append([]int(nil), p.ReferenceLine...)
+	q.Flags = cloneStrings(p.Flags)
+	return q
+}
+
+func cloneStrings(ss []string) []string {
+	if ss == nil {
+		return nil
+	}
+	return append([]string{}, ss...)
+}
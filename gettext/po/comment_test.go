@@ -6,16 +6,14 @@ package po
 
 import (
 	"reflect"
+	"strings"
 	"testing"
 )
 
 func TestPoComment(t *testing.T) {
 	var x Comment
 	for i := 0; i < len(testPoComments); i++ {
-		if i != 2 {
-			continue
-		}
-		err := x.readPoComment(newLineReader(testPoComments[i].Data))
+		err := x.readPoComment(newLineReader(strings.NewReader(testPoComments[i].Data)))
 		if err != nil {
 			t.Fatalf("%d: %v", i, err)
 		}
@@ -94,6 +92,23 @@ var testPoComments = []testPoComment{
 		},
 	},
 
+	testPoComment{
+		CheckStringer: true,
+		Data: `#, fuzzy, c-format
+#| msgctxt "menu"
+#| msgid "%d file was removed"
+#| msgid_plural ""
+#| "%d files were removed from the archive, the archive is now smaller than "
+#| "before"
+`,
+		PoComment: Comment{
+			Flags:           []string{"fuzzy", "c-format"},
+			PrevMsgContext:  "menu",
+			PrevMsgId:       "%d file was removed",
+			PrevMsgIdPlural: "%d files were removed from the archive, the archive is now smaller than before",
+		},
+	},
+
 	// --------------------------------------------------------------
 	// CheckStringer: false
 	// --------------------------------------------------------------
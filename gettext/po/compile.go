@@ -0,0 +1,284 @@
+// Copyright 2013 ChaiShushan <chaishushan{AT}gmail.com>. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package po
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/faxal/gettext-go/gettext/mo"
+	"github.com/faxal/gettext-go/gettext/plural"
+)
+
+// CompileOptions are the options of Compile.
+type CompileOptions struct {
+	UseFuzzy    bool // compile the fuzzy messages, like msgfmt --use-fuzzy
+	CheckFormat bool // check the verbs of the "go-format" messages, like msgfmt --check-format
+	CheckHeader bool // check the fields of the header, like msgfmt --check-header
+	CheckPlural bool // check the number of msgstr[N] is the nplurals of Plural-Forms
+}
+
+// CompileError is a problem of the po file found by Compile.
+type CompileError struct {
+	Line int    // the start line of the message, 0 means unknown
+	Msg  string // description of error
+}
+
+func (e *CompileError) Error() string {
+	if e.Line > 0 {
+		return fmt.Sprintf("gettext: line %d: %s", e.Line, e.Msg)
+	}
+	return fmt.Sprintf("gettext: %s", e.Msg)
+}
+
+// CompileErrors are all the problems found by Compile.
+type CompileErrors []*CompileError
+
+func (e CompileErrors) Error() string {
+	var s []string
+	for _, err := range e {
+		s = append(s, err.Error())
+	}
+	return strings.Join(s, "\n")
+}
+
+// Statistics are the numbers of the messages, like msgfmt --statistics.
+//
+// The header and the obsolete messages are not counted.
+type Statistics struct {
+	Translated   int
+	Fuzzy        int
+	Untranslated int
+}
+
+// String returns the statistics like GNU's msgfmt:
+//	3 translated messages, 1 fuzzy translation, 2 untranslated messages.
+func (s Statistics) String() string {
+	plural := func(n int, one, other string) string {
+		if n == 1 {
+			return fmt.Sprintf("%d %s", n, one)
+		}
+		return fmt.Sprintf("%d %s", n, other)
+	}
+	var buf bytes.Buffer
+	buf.WriteString(plural(s.Translated, "translated message", "translated messages"))
+	if s.Fuzzy != 0 {
+		buf.WriteString(", " + plural(s.Fuzzy, "fuzzy translation", "fuzzy translations"))
+	}
+	if s.Untranslated != 0 {
+		buf.WriteString(", " + plural(s.Untranslated, "untranslated message", "untranslated messages"))
+	}
+	buf.WriteString(".")
+	return buf.String()
+}
+
+// Statistics returns the numbers of the translated, fuzzy and untranslated
+// messages.
+func (f *File) Statistics() Statistics {
+	var s Statistics
+	for i := 0; i < len(f.Messages); i++ {
+		msg := &f.Messages[i]
+		switch {
+		case msg.Obsolete:
+		case !msg.hasMsgStr():
+			s.Untranslated++
+		case msg.GetFuzzy():
+			s.Fuzzy++
+		default:
+			s.Translated++
+		}
+	}
+	return s
+}
+
+// hasMsgStr reports whether the message is translated for msgfmt,
+// which only checks the first msgstr.
+func (p *Message) hasMsgStr() bool {
+	if p.MsgIdPlural == "" {
+		return p.MsgStr != ""
+	}
+	return len(p.MsgStrPlural) != 0 && p.MsgStrPlural[0] != ""
+}
+
+// Compile compiles the po file to a mo file, like GNU's msgfmt.
+//
+// The obsolete and untranslated messages are skipped, and the fuzzy
+// messages are skipped unless opts.UseFuzzy. The header is kept even
+// if it's fuzzy. The mo file data is byte-compatible with msgfmt.
+//
+// The returned error is a CompileErrors if any check fails.
+func (f *File) Compile(opts *CompileOptions) (*mo.File, error) {
+	if opts == nil {
+		opts = &CompileOptions{}
+	}
+	var errs CompileErrors
+	errorf := func(line int, format string, args ...interface{}) {
+		errs = append(errs, &CompileError{Line: line, Msg: fmt.Sprintf(format, args...)})
+	}
+
+	if opts.CheckHeader {
+		f.MimeHeader.check(errorf)
+	}
+	var (
+		forms        *plural.Forms
+		missingForms bool // the error is reported once
+	)
+	if opts.CheckPlural && f.MimeHeader.PluralForms != "" {
+		var err error
+		if forms, err = plural.ParseForms(f.MimeHeader.PluralForms); err != nil {
+			errorf(f.MimeHeader.StartLine, "invalid Plural-Forms: %v", err)
+		}
+	}
+
+	file := &mo.File{
+		MimeHeader: mo.ParseHeader(f.MimeHeader.msgStr()),
+	}
+	for i := 0; i < len(f.Messages); i++ {
+		msg := &f.Messages[i]
+		if msg.Obsolete || !msg.hasMsgStr() {
+			continue
+		}
+		if msg.GetFuzzy() && !opts.UseFuzzy {
+			continue
+		}
+		if opts.CheckPlural && msg.MsgIdPlural != "" {
+			switch {
+			case f.MimeHeader.PluralForms == "":
+				if !missingForms {
+					errorf(msg.StartLine, "message has plural forms, but the header lacks Plural-Forms")
+					missingForms = true
+				}
+			case forms != nil && len(msg.MsgStrPlural) != forms.NPlurals:
+				errorf(msg.StartLine, "nplurals = %d, but the message has %d plural forms", forms.NPlurals, len(msg.MsgStrPlural))
+			}
+		}
+		if opts.CheckFormat && containsString(msg.Flags, "go-format") {
+			msg.checkFormat(errorf)
+		}
+		file.Messages = append(file.Messages, mo.Message{
+			MsgContext:   msg.MsgContext,
+			MsgId:        msg.MsgId,
+			MsgIdPlural:  msg.MsgIdPlural,
+			MsgStr:       msg.MsgStr,
+			MsgStrPlural: cloneStrings(msg.MsgStrPlural),
+		})
+	}
+	if len(errs) != 0 {
+		return nil, errs
+	}
+	return file, nil
+}
+
+// check checks the fields of the header like msgfmt --check-header,
+// the required fields are not missing and are not the template values.
+func (p *Header) check(errorf func(line int, format string, args ...interface{})) {
+	for _, v := range []struct {
+		name, template string
+	}{
+		{"Project-Id-Version", "PACKAGE VERSION"},
+		{"PO-Revision-Date", "YEAR-MO-DA HO:MI+ZONE"},
+		{"Last-Translator", "FULL NAME <EMAIL@ADDRESS>"},
+		{"Language-Team", "LANGUAGE <LL@li.org>"},
+		{"MIME-Version", ""},
+		{"Content-Type", "text/plain; charset=CHARSET"},
+		{"Content-Transfer-Encoding", "ENCODING"},
+	} {
+		switch val := *p.field(v.name); {
+		case val == "":
+			errorf(p.StartLine, "header field %q missing in header", v.name)
+		case v.template != "" && val == v.template:
+			errorf(p.StartLine, "header field %q still has the initial default value", v.name)
+		}
+	}
+}
+
+// reGoFormat matches the verbs of the fmt package, like "%d", "%-8.2f"
+// and "%[1]*s", and "%%".
+var reGoFormat = regexp.MustCompile(`%(%|[-+# 0]*(\[\d+\])?(\*|\d+)?(\.(\[\d+\])?(\*|\d+)?)?(\[\d+\])?[vTtbcdoOqxXUeEfFgGsp])`)
+
+// checkFormat checks the msgstr has the same verbs as the msgid.
+//
+// A msgstr[N] may omit the arguments of msgid_plural, like the
+// msgstr[0] "one file" of the msgid_plural "%d files".
+func (p *Message) checkFormat(errorf func(line int, format string, args ...interface{})) {
+	if p.MsgIdPlural == "" {
+		if err := checkGoFormat(p.MsgId, p.MsgStr, false); err != "" {
+			errorf(p.StartLine, "msgstr: %s", err)
+		}
+		return
+	}
+	for i, s := range p.MsgStrPlural {
+		if err := checkGoFormat(p.MsgIdPlural, s, true); err != "" {
+			errorf(p.StartLine, "msgstr[%d]: %s", i, err)
+		}
+	}
+}
+
+// checkGoFormat returns the description of the mismatched verbs of
+// msgid and msgstr, or "" if they match.
+func checkGoFormat(msgid, msgstr string, omitArgs bool) string {
+	a, b := goFormatArgs(msgid), goFormatArgs(msgstr)
+	var args []int
+	for n := range b {
+		args = append(args, n)
+	}
+	sort.Ints(args)
+	for _, n := range args {
+		if v, verb := a[n], b[n]; v == "" {
+			return fmt.Sprintf("argument %d doesn't exist in msgid", n)
+		} else if v != verb {
+			return fmt.Sprintf("argument %d is %q in msgid, but %q in msgstr", n, v, verb)
+		}
+	}
+	if !omitArgs && len(a) != len(b) {
+		return fmt.Sprintf("msgid has %d arguments, but msgstr has %d", len(a), len(b))
+	}
+	return ""
+}
+
+// IsGoFormat reports whether s contains the verbs of the fmt package,
+// the message of s has the "go-format" flag, like xgettext-go.
+func IsGoFormat(s string) bool {
+	for _, verb := range reGoFormat.FindAllString(s, -1) {
+		if verb != "%%" {
+			return true
+		}
+	}
+	return false
+}
+
+// goFormatArgs returns the verbs of the arguments of the format string,
+// by the argument numbers which start at 1. The "*" argument has the
+// verb "*".
+func goFormatArgs(s string) map[int]string {
+	args := make(map[int]string)
+	n := 1
+	for _, m := range reGoFormat.FindAllStringSubmatch(s, -1) {
+		if m[1] == "%" {
+			continue
+		}
+		// the explicit argument index, like "%[2]d"
+		index := func(s string) {
+			if s != "" {
+				n, _ = strconv.Atoi(s[1 : len(s)-1])
+			}
+		}
+		index(m[2])
+		if m[3] == "*" {
+			args[n], n = "*", n+1
+		}
+		index(m[5])
+		if m[6] == "*" {
+			args[n], n = "*", n+1
+		}
+		index(m[7])
+		args[n], n = m[0][len(m[0])-1:], n+1
+	}
+	return args
+}
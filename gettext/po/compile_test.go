@@ -0,0 +1,206 @@
+// Copyright 2013 ChaiShushan <chaishushan{AT}gmail.com>. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package po
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/faxal/gettext-go/gettext/charset"
+	"github.com/faxal/gettext-go/gettext/mo"
+)
+
+func TestCompile_msgfmt(t *testing.T) {
+	names, err := filepath.Glob("../../testdata/*.po")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range names {
+		moData, err := ioutil.ReadFile(strings.TrimSuffix(name, ".po") + ".mo")
+		if os.IsNotExist(err) {
+			continue
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		f, err := Load(name)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		m, err := f.Compile(nil)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if !bytes.Equal(m.Data(), moData) {
+			t.Fatalf("%s: the mo file is not the same as msgfmt's", name)
+		}
+	}
+}
+
+func TestFromMO(t *testing.T) {
+	names, err := filepath.Glob("../../testdata/*.mo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range names {
+		m, err := mo.Load(name)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if !bytes.Equal(FromMO(m).ToMO().Data(), m.Data()) {
+			t.Fatalf("%s: FromMO(m).ToMO() is not the same as m", name)
+		}
+		// the po file is in the charset of the header, m.String() is UTF-8
+		s := m.String()
+		if enc := charset.Lookup(m.MimeHeader.Charset()); enc != nil {
+			if s, err = enc.EncodeString(s); err != nil {
+				t.Fatalf("%s: %v", name, err)
+			}
+		}
+		f, err := LoadData([]byte(s))
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if !bytes.Equal(f.ToMO().Data(), m.Data()) {
+			t.Fatalf("%s: m.String() is not the same as m", name)
+		}
+	}
+}
+
+func TestIsGoFormat(t *testing.T) {
+	for _, v := range []struct {
+		s  string
+		ok bool
+	}{
+		{"Hello", false},
+		{"100%", false},
+		{"100%% done", false},
+		{"%d file", true},
+		{"%-8.2f", true},
+		{"%[1]*s", true},
+		{"%v%%", true},
+		{"%", false},
+	} {
+		if ok := IsGoFormat(v.s); ok != v.ok {
+			t.Fatalf("%q: expect = %v, got = %v", v.s, v.ok, ok)
+		}
+	}
+}
+
+func TestCompile_checks(t *testing.T) {
+	f, err := LoadData([]byte(testCompilePoData))
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := f.Compile(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(m.Messages) != 3 {
+		t.Fatalf("expect = 3 messages, got = %d", len(m.Messages))
+	}
+	if m, _ = f.Compile(&CompileOptions{UseFuzzy: true}); len(m.Messages) != 4 {
+		t.Fatalf("expect = 4 messages, got = %d", len(m.Messages))
+	}
+
+	_, err = f.Compile(&CompileOptions{CheckFormat: true, CheckHeader: true, CheckPlural: true})
+	errs, ok := err.(CompileErrors)
+	if !ok {
+		t.Fatalf("expect CompileErrors, got = %v", err)
+	}
+	var got []string
+	for _, err := range errs {
+		got = append(got, err.Error())
+	}
+	expect := []string{
+		`gettext: line 1: header field "Project-Id-Version" still has the initial default value`,
+		`gettext: line 1: header field "Last-Translator" missing in header`,
+		`gettext: line 11: nplurals = 2, but the message has 3 plural forms`,
+		`gettext: line 11: msgstr[2]: argument 1 is "d" in msgid, but "s" in msgstr`,
+		`gettext: line 18: msgstr: msgid has 2 arguments, but msgstr has 1`,
+	}
+	if !reflect.DeepEqual(got, expect) {
+		t.Fatalf("expect = %q, got = %q", expect, got)
+	}
+}
+
+func TestStatistics(t *testing.T) {
+	f, err := LoadData([]byte(testCompilePoData))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := f.Statistics()
+	if expect := (Statistics{Translated: 3, Fuzzy: 1, Untranslated: 1}); s != expect {
+		t.Fatalf("expect = %v, got = %v", expect, s)
+	}
+	for i, v := range []struct {
+		s      Statistics
+		expect string
+	}{
+		{Statistics{}, "0 translated messages."},
+		{Statistics{Translated: 1}, "1 translated message."},
+		{Statistics{3, 1, 2}, "3 translated messages, 1 fuzzy translation, 2 untranslated messages."},
+		{Statistics{1, 2, 1}, "1 translated message, 2 fuzzy translations, 1 untranslated message."},
+	} {
+		if got := v.s.String(); got != v.expect {
+			t.Fatalf("%d: expect = %q, got = %q", i, v.expect, got)
+		}
+	}
+}
+
+func TestGoFormatArgs(t *testing.T) {
+	for i, v := range []struct {
+		s      string
+		expect map[int]string
+	}{
+		{"100%%", map[int]string{}},
+		{"%d %s", map[int]string{1: "d", 2: "s"}},
+		{"%[2]s %[1]d", map[int]string{1: "d", 2: "s"}},
+		{"%*d %.*f", map[int]string{1: "*", 2: "d", 3: "*", 4: "f"}},
+		{"%-8.2f%v", map[int]string{1: "f", 2: "v"}},
+	} {
+		if got := goFormatArgs(v.s); !reflect.DeepEqual(got, v.expect) {
+			t.Fatalf("%d: expect = %v, got = %v", i, v.expect, got)
+		}
+	}
+}
+
+const testCompilePoData = `msgid ""
+msgstr ""
+"Project-Id-Version: PACKAGE VERSION\n"
+"PO-Revision-Date: 2014-01-01 10:00+0800\n"
+"Language-Team: German\n"
+"MIME-Version: 1.0\n"
+"Content-Type: text/plain; charset=UTF-8\n"
+"Content-Transfer-Encoding: 8bit\n"
+"Plural-Forms: nplurals=2; plural=(n != 1);\n"
+
+#, go-format
+msgid "%d file"
+msgid_plural "%d files"
+msgstr[0] "eine Datei"
+msgstr[1] "%d Dateien"
+msgstr[2] "%s Dateien"
+
+#, go-format
+msgid "%[2]s has %[1]d files"
+msgstr "%[2]s hat Dateien"
+
+#, go-format
+msgid "%s is open"
+msgstr "%s ist offen"
+
+#, fuzzy
+msgid "Close"
+msgstr "Schließen"
+
+msgid "Untranslated"
+msgstr ""
+`
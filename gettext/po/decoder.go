@@ -0,0 +1,178 @@
+// Copyright 2013 ChaiShushan <chaishushan{AT}gmail.com>. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package po
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/faxal/gettext-go/gettext/charset"
+)
+
+// SyntaxError records a syntax error and the position in a po file.
+type SyntaxError struct {
+	Line   int    // line number, starting at 1
+	Column int    // byte column number, starting at 1
+	Msg    string // description of error
+}
+
+func newSyntaxError(pos, offset int, format string, args ...interface{}) *SyntaxError {
+	return &SyntaxError{
+		Line:   pos + 1,
+		Column: offset + 1,
+		Msg:    fmt.Sprintf(format, args...),
+	}
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("gettext: line %d, column %d: %s", e.Line, e.Column, e.Msg)
+}
+
+// Decoder reads and decodes the messages of a po file from an input stream.
+//
+// The messages are decoded to UTF-8 by the charset of the header's
+// Content-Type, a file with an unknown charset is not decoded.
+//
+// Examples:
+//	dec := po.NewDecoder(r)
+//	dec.Recover = true
+//	for {
+//		var msg po.Message
+//		if err := dec.Decode(&msg); err == io.EOF {
+//			break
+//		} else if err != nil {
+//			log.Fatal(err)
+//		}
+//		fmt.Println(msg.MsgId)
+//	}
+//	for _, err := range dec.Errors() {
+//		fmt.Println(err)
+//	}
+type Decoder struct {
+	// Recover makes Decode skip the broken entries instead of failing,
+	// the syntax errors are collected and can be got by Errors.
+	Recover bool
+
+	r      *lineReader
+	header Header
+	errs   []*SyntaxError
+	tail   string // the text after the last entry
+}
+
+// NewDecoder returns a new decoder that reads from r.
+func NewDecoder(r io.Reader) *Decoder {
+	return &Decoder{
+		r: newLineReader(r),
+	}
+}
+
+// Decode reads the next message from its input and stores it in msg.
+// At the end of the input, Decode returns io.EOF.
+//
+// The header entry is not a message, it is decoded and can be got
+// by Header.
+func (d *Decoder) Decode(msg *Message) error {
+	for {
+		var m Message
+		if err := m.readPoEntry(d.r); err != nil {
+			if err == io.EOF {
+				d.tail = d.r.takeRaw()
+			}
+			e, ok := err.(*SyntaxError)
+			if !ok || !d.Recover {
+				return err
+			}
+			d.errs = append(d.errs, e)
+			d.skipEntry(e.Line)
+			d.r.takeRaw()
+			continue
+		}
+		raw := d.r.takeRaw()
+		if m.MsgId == "" && m.MsgContext == "" && !m.Obsolete {
+			if err := d.decodeHeader(&m, raw); err != nil {
+				return err
+			}
+			continue
+		}
+		m.raw = &rawMessage{text: raw, msg: m.clone()}
+		*msg = m
+		return nil
+	}
+}
+
+// decodeHeader decodes the header entry, and the charset of the header
+// is used to decode the next lines.
+func (d *Decoder) decodeHeader(m *Message, raw string) error {
+	d.header = Header{}
+	d.header.parseHeader(m)
+	if d.r.enc == nil {
+		if enc := charset.Lookup(d.header.Charset()); enc != nil && !enc.IsUTF8() {
+			// the header entry was read before the charset is known
+			raw = enc.DecodeString(raw)
+			line := m.StartLine
+			if err := m.readPoEntry(newLineReader(strings.NewReader(raw))); err != nil {
+				return err
+			}
+			m.StartLine = line
+			d.header = Header{}
+			d.header.parseHeader(m)
+			d.r.setEncoding(enc)
+		}
+	}
+	d.header.raw = &rawHeader{text: raw, header: d.header.clone()}
+	return nil
+}
+
+// Header returns the decoded header entry.
+func (d *Decoder) Header() Header {
+	return d.header
+}
+
+// Errors returns the syntax errors collected in recovery mode.
+func (d *Decoder) Errors() []*SyntaxError {
+	return d.errs
+}
+
+// skipEntry skips the lines of the broken entry, until a blank line
+// or a line starts a new entry after the line of the error.
+func (d *Decoder) skipEntry(line int) {
+	for {
+		s, pos, err := d.r.currentLine()
+		if err != nil {
+			return
+		}
+		if pos+1 > line {
+			if reBlankLine.MatchString(s) || reComment.MatchString(s) {
+				return
+			}
+			if keyword, _, _ := splitKeyword(s); keyword == "msgctxt" || keyword == "msgid" {
+				return
+			}
+		}
+		d.r.readLine()
+	}
+}
+
+// decodeFile decodes all the messages of the input.
+func (d *Decoder) decodeFile() (*File, error) {
+	var file File
+	for {
+		var msg Message
+		if err := d.Decode(&msg); err != nil {
+			if err == io.EOF {
+				break
+			}
+			return nil, err
+		}
+		file.Messages = append(file.Messages, msg)
+	}
+	file.MimeHeader = d.header
+	file.raw = &rawFile{
+		tail:       d.tail,
+		lineEnding: d.r.lineEnding,
+	}
+	return &file, nil
+}
@@ -0,0 +1,249 @@
+// Copyright 2013 ChaiShushan <chaishushan{AT}gmail.com>. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package po
+
+import (
+	"bytes"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDecoder(t *testing.T) {
+	dec := NewDecoder(strings.NewReader(testDecoderPoData))
+	var msgs []Message
+	for {
+		var msg Message
+		if err := dec.Decode(&msg); err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatal(err)
+		}
+		msgs = append(msgs, msg)
+	}
+	if s := dec.Header().Language; s != "de" {
+		t.Fatalf("bad header: %v", dec.Header())
+	}
+	if len(msgs) != len(testDecoderMessages) {
+		t.Fatalf("expect = %d messages, got = %d", len(testDecoderMessages), len(msgs))
+	}
+	for i, v := range msgs {
+		v.StartLine, v.raw = 0, nil
+		if !reflect.DeepEqual(&v, &testDecoderMessages[i]) {
+			t.Fatalf("%d: expect = %#v, got = %#v", i, testDecoderMessages[i], v)
+		}
+	}
+}
+
+func TestDecoder_SyntaxError(t *testing.T) {
+	for i, v := range []struct {
+		data   string
+		line   int
+		column int
+	}{
+		{"msgid \"a\"\nmsgstr \"b", 2, 8},
+		{"msgid \"a\"\nmsgstr \"\\q\"", 2, 9},
+		{"msgid \"a\"\nmsgstr \"b\"\n\"c\"x", 3, 1},
+		{"msgid \"a\"\n\nmsgstr \"b\"", 1, 1},
+		{"msgstr \"b\"", 1, 1},
+		{"msgid \"a\"\nmsgid \"b\"\nmsgstr \"\"", 2, 1},
+		{"msgid \"a\"\nmsgstr[0] \"b\"", 2, 1},
+		{"msgid \"a\"\nmsgid_plural \"b\"\nmsgstr[1] \"b\"", 3, 7},
+		{"msgid \"a\"\nmsgid_plural \"b\"\nmsgstr \"b\"", 3, 1},
+		{"# comment\nmsgid \"a\"\nmsgstr \"b\"\nbad line", 4, 1},
+		{"msgid \"a\"\nmsgstr \"b\"\n\"c", 3, 1},
+		{"msgid a\nmsgstr \"b\"", 1, 7},
+	} {
+		_, err := LoadData([]byte(v.data))
+		e, ok := err.(*SyntaxError)
+		if !ok {
+			t.Fatalf("%d: expect *SyntaxError, got = %v", i, err)
+		}
+		if e.Line != v.line || e.Column != v.column {
+			t.Fatalf("%d: expect = %d:%d, got = %v", i, v.line, v.column, e)
+		}
+	}
+}
+
+func TestDecoder_Recover(t *testing.T) {
+	dec := NewDecoder(strings.NewReader(testDecoderBadPoData))
+	dec.Recover = true
+	var ids []string
+	for {
+		var msg Message
+		if err := dec.Decode(&msg); err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatal(err)
+		}
+		ids = append(ids, msg.MsgId)
+	}
+	if expect := []string{"one", "three", "six", "seven"}; !reflect.DeepEqual(ids, expect) {
+		t.Fatalf("expect = %v, got = %v", expect, ids)
+	}
+	var lines []int
+	for _, err := range dec.Errors() {
+		lines = append(lines, err.Line)
+	}
+	if expect := []int{5, 12, 15}; !reflect.DeepEqual(lines, expect) {
+		t.Fatalf("expect = %v, got = %v", expect, dec.Errors())
+	}
+}
+
+func TestDecoder_Obsolete(t *testing.T) {
+	f, err := LoadData([]byte(testDecoderObsoletePoData))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(f.Messages) != len(testDecoderObsoleteMessages) {
+		t.Fatalf("expect = %d messages, got = %d", len(testDecoderObsoleteMessages), len(f.Messages))
+	}
+	for i, v := range f.Messages {
+		v.StartLine, v.raw = 0, nil
+		if !reflect.DeepEqual(&v, &testDecoderObsoleteMessages[i]) {
+			t.Fatalf("%d: expect = %#v, got = %#v", i, testDecoderObsoleteMessages[i], v)
+		}
+	}
+	if f.raw.tail != "" {
+		t.Fatalf("expect empty tail, got = %q", f.raw.tail)
+	}
+
+	var buf bytes.Buffer
+	enc := NewEncoder(&buf)
+	enc.Reformat = true
+	if err := enc.Encode(f); err != nil {
+		t.Fatal(err)
+	}
+	if got := buf.String(); got != testDecoderObsoletePoData {
+		t.Fatalf("expect = %q, got = %q", testDecoderObsoletePoData, got)
+	}
+	f.Messages = append(f.Messages, Message{MsgId: "new", Obsolete: true})
+	if got := f.String(); !strings.HasSuffix(got, "\n#~ msgid \"new\"\n#~ msgstr \"\"\n") {
+		t.Fatalf("bad obsolete message: %q", got)
+	}
+}
+
+const testDecoderPoData = `# header comment
+msgid ""
+msgstr ""
+"Language: de\n"
+"Plural-Forms: nplurals=2; plural=(n != 1);\n"
+
+#. extracted
+#: main.go:10 util.go:20
+#, c-format
+msgctxt "main.main"
+msgid "Hello, %s!"
+msgstr "Hallo, %s!"
+
+msgid "escapes \"\t\101\x41\\"
+msgstr ""
+"line 1\n"
+"line 2"
+msgid "%d file"
+msgid_plural "%d files"
+msgstr[0] "%d Datei"
+msgstr[1] "%d Dateien"
+
+# trailing comment
+`
+
+var testDecoderMessages = []Message{
+	{
+		Comment: Comment{
+			ExtractedComment: "extracted",
+			ReferenceFile:    []string{"main.go", "util.go"},
+			ReferenceLine:    []int{10, 20},
+			Flags:            []string{"c-format"},
+		},
+		MsgContext: "main.main",
+		MsgId:      "Hello, %s!",
+		MsgStr:     "Hallo, %s!",
+	},
+	{
+		MsgId:  "escapes \"\tAA\\",
+		MsgStr: "line 1\nline 2",
+	},
+	{
+		MsgId:        "%d file",
+		MsgIdPlural:  "%d files",
+		MsgStrPlural: []string{"%d Datei", "%d Dateien"},
+	},
+}
+
+const testDecoderBadPoData = `msgid "one"
+msgstr "1"
+
+msgid "two"
+msgstr "2
+"more"
+
+msgid "three"
+msgstr "3"
+
+msgid "four"
+bad line
+msgstr "4"
+msgid "five"
+msgstr "5" junk
+
+msgid "six"
+msgstr "6"
+msgid "seven"
+msgstr "7"
+`
+
+const testDecoderObsoletePoData = `msgid "open"
+msgstr "offen"
+
+# translator
+#~ msgid "Closed"
+#~ msgstr "Geschlossen"
+
+#, fuzzy
+#~| msgid "Open"
+#~ msgctxt "Lock state"
+#~ msgid "Opened"
+#~ msgstr ""
+#~ "Offen\n"
+#~ "Geöffnet"
+
+#~| msgid "Close"
+#~ msgid "%d lock"
+#~ msgid_plural "%d locks"
+#~ msgstr[0] "%d Schloss"
+#~ msgstr[1] "%d Schlösser"
+`
+
+var testDecoderObsoleteMessages = []Message{
+	{
+		MsgId:  "open",
+		MsgStr: "offen",
+	},
+	{
+		Comment:  Comment{TranslatorComment: "translator"},
+		MsgId:    "Closed",
+		MsgStr:   "Geschlossen",
+		Obsolete: true,
+	},
+	{
+		Comment: Comment{
+			Flags:     []string{"fuzzy"},
+			PrevMsgId: "Open",
+		},
+		MsgContext: "Lock state",
+		MsgId:      "Opened",
+		MsgStr:     "Offen\nGeöffnet",
+		Obsolete:   true,
+	},
+	{
+		Comment:      Comment{PrevMsgId: "Close"},
+		MsgId:        "%d lock",
+		MsgIdPlural:  "%d locks",
+		MsgStrPlural: []string{"%d Schloss", "%d Schlösser"},
+		Obsolete:     true,
+	},
+}
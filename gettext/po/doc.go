@@ -18,6 +18,63 @@ Examples:
 		fmt.Printf("%v", poFile)
 	}
 
+Use Decoder to read the messages one by one, and to report all the
+syntax errors of a broken file:
+
+	dec := po.NewDecoder(os.Stdin)
+	dec.Recover = true
+	for {
+		var msg po.Message
+		if err := dec.Decode(&msg); err != nil {
+			break
+		}
+	}
+	for _, err := range dec.Errors() {
+		fmt.Println(err) // gettext: line 12, column 8: unterminated string
+	}
+
+Saving a loaded file keeps the unchanged messages as they were, so
+the load-then-save of a file written by GNU's tools has no diff. Use
+Encoder to wrap the lines at another page width, or to sort messages:
+
+	enc := po.NewEncoder(os.Stdout)
+	enc.Width = 100
+	enc.Sort = true
+	if err := enc.Encode(poFile); err != nil {
+		log.Fatal(err)
+	}
+
+The legacy charsets like EUC-KR are decoded to UTF-8 by the charset of
+the header's Content-Type, and the file is encoded to it again when saving.
+Set Encoder.Charset to convert the file to another charset, like GNU's
+msgconv:
+
+	enc := po.NewEncoder(os.Stdout)
+	enc.Charset = "UTF-8"
+	if err := enc.Encode(poFile); err != nil {
+		log.Fatal(err)
+	}
+
+Use Merge to update the translations to a new po template file, the
+changed msgids are translated by the similar old messages and marked
+fuzzy, like GNU's msgmerge:
+
+	newFile := po.Merge(poFile, potFile, nil)
+
+Use Compile to compile a po file to a mo file like GNU's msgfmt:
+
+	moFile, err := poFile.Compile(&po.CompileOptions{CheckFormat: true})
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println(poFile.Statistics()) // 3 translated messages, 1 fuzzy translation.
+
+Use FromMO to decompile a mo file back to a po file like GNU's msgunfmt,
+and ToMO to convert it again:
+
+	poFile := po.FromMO(moFile)
+	moFile = poFile.ToMO()
+
 The GNU PO file specification is at
 http://www.gnu.org/software/gettext/manual/html_node/PO-Files.html.
 */
@@ -0,0 +1,348 @@
+// Copyright 2013 ChaiShushan <chaishushan{AT}gmail.com>. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package po
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/faxal/gettext-go/gettext/charset"
+)
+
+// defaultWidth is the default page width of msgcat.
+const defaultWidth = 79
+
+// Encoder writes po files to an output stream.
+//
+// The unchanged messages of a loaded file are written as they were
+// loaded, so saving a loaded file produces no diff. The changed and
+// new messages are written like GNU's msgcat.
+//
+// Examples:
+//	enc := po.NewEncoder(os.Stdout)
+//	enc.Width = 100
+//	if err := enc.Encode(poFile); err != nil {
+//		log.Fatal(err)
+//	}
+type Encoder struct {
+	Width      int    // page width of line wrapping, 0 means 79
+	NoWrap     bool   // don't wrap the long lines, like msgcat --no-wrap
+	Sort       bool   // sort the messages, the default keeps the original order
+	LineEnding string // "\n" or "\r\n", empty means the line ending of the loaded file
+	Reformat   bool   // write all the messages like msgcat, even the unchanged ones
+	Charset    string // charset of the output, empty means the charset of the header
+
+	w io.Writer
+}
+
+// NewEncoder returns a new encoder that writes to w.
+func NewEncoder(w io.Writer) *Encoder {
+	return &Encoder{w: w}
+}
+
+// Encode writes the po file to the stream.
+//
+// If any of Width, NoWrap and Reformat is set, all the messages
+// are written in the new format.
+//
+// The file is encoded to the charset of the header's Content-Type, like
+// it was loaded. If Charset is another charset, the Content-Type is
+// changed to it, like msgconv --to-code. The chars which the charset
+// doesn't have are written as '?', and an error is returned. A file
+// with an unknown charset is not encoded.
+func (e *Encoder) Encode(f *File) error {
+	enc := charset.Lookup(f.MimeHeader.Charset())
+	if e.Charset != "" {
+		to := charset.Lookup(e.Charset)
+		if to == nil {
+			return fmt.Errorf("gettext: unknown charset %q", e.Charset)
+		}
+		if to != enc {
+			g := *f
+			g.MimeHeader = f.MimeHeader.clone()
+			g.MimeHeader.raw = f.MimeHeader.raw
+			g.MimeHeader.ContentType = charset.SetContentTypeCharset(f.MimeHeader.ContentType, e.Charset)
+			f, enc = &g, to
+		}
+	}
+	if enc == nil {
+		enc = charset.UTF8
+	}
+
+	var (
+		reformat   = e.Reformat || e.NoWrap || (e.Width != 0 && e.Width != defaultWidth)
+		lineEnding = e.LineEnding
+		width      = e.Width
+	)
+	if lineEnding == "" {
+		lineEnding = "\n"
+		if f.raw != nil && f.raw.lineEnding != "" {
+			lineEnding = f.raw.lineEnding
+		}
+	}
+	if width <= 0 {
+		width = defaultWidth
+	}
+	if e.NoWrap {
+		width = 0
+	}
+
+	var buf bytes.Buffer
+	w := newPoWriter(&buf, width)
+	hasEntry := false
+	writeEntry := func(raw string, changed bool, write func()) {
+		if !changed && !reformat {
+			buf.WriteString(raw)
+			hasEntry = true
+			return
+		}
+		// the canonical format uses "\n", convert it to the line ending
+		var entry bytes.Buffer
+		if hasEntry {
+			entry.WriteString("\n")
+		}
+		w.buf = &entry
+		write()
+		s := entry.String()
+		if lineEnding != "\n" {
+			s = strings.Replace(s, "\n", lineEnding, -1)
+		}
+		buf.WriteString(s)
+		hasEntry = true
+	}
+
+	if h := &f.MimeHeader; f.raw == nil || h.raw != nil || !reflect.DeepEqual(h, &Header{}) {
+		var raw string
+		if h.raw != nil {
+			raw = h.raw.text
+		}
+		writeEntry(raw, h.isChanged(), func() { w.writeHeader(h) })
+	}
+
+	messages := f.Messages
+	if e.Sort {
+		messages = append([]Message(nil), f.Messages...)
+		sort.Stable(byMessages(messages))
+	}
+	for i := 0; i < len(messages); i++ {
+		msg := &messages[i]
+		var raw string
+		if msg.raw != nil {
+			raw = msg.raw.text
+		}
+		writeEntry(raw, msg.isChanged(), func() { w.writeMessage(msg) })
+	}
+	if f.raw != nil && !reformat {
+		buf.WriteString(f.raw.tail)
+	}
+
+	data := buf.Bytes()
+	if f.raw != nil && f.raw.lineEnding != "" && f.raw.lineEnding != lineEnding {
+		// the raw text keeps the line ending of the loaded file
+		data = bytes.Replace(data, []byte("\r\n"), []byte("\n"), -1)
+		if lineEnding != "\n" {
+			data = bytes.Replace(data, []byte("\n"), []byte(lineEnding), -1)
+		}
+	}
+	data, err := enc.Encode(data)
+	if _, werr := e.w.Write(data); err == nil {
+		err = werr
+	}
+	return err
+}
+
+// poWriter writes the po entries like GNU's msgcat.
+type poWriter struct {
+	buf   *bytes.Buffer
+	width int // page width, 0 means no wrap
+}
+
+func newPoWriter(buf *bytes.Buffer, width int) *poWriter {
+	return &poWriter{buf: buf, width: width}
+}
+
+func (w *poWriter) writeHeader(p *Header) {
+	w.writeComment(&p.Comment, false)
+	w.writeString("", "msgid", "", false)
+	w.wrapString("", "msgstr", p.msgStr(), false, true)
+}
+
+func (w *poWriter) writeMessage(p *Message) {
+	noWrap := false
+	for _, s := range p.Flags {
+		if s == "no-wrap" {
+			noWrap = true
+		}
+	}
+	prefix := "" // the prefix of the obsolete message lines
+	if p.Obsolete {
+		prefix = "#~ "
+	}
+	w.writeComment(&p.Comment, p.Obsolete)
+	if p.MsgContext != "" {
+		w.writeString(prefix, "msgctxt", p.MsgContext, noWrap)
+	}
+	w.writeString(prefix, "msgid", p.MsgId, noWrap)
+	if p.MsgIdPlural == "" {
+		w.writeString(prefix, "msgstr", p.MsgStr, noWrap)
+		return
+	}
+	w.writeString(prefix, "msgid_plural", p.MsgIdPlural, noWrap)
+	for i := 0; i < len(p.MsgStrPlural); i++ {
+		w.writeString(prefix, fmt.Sprintf("msgstr[%d]", i), p.MsgStrPlural[i], noWrap)
+	}
+	if len(p.MsgStrPlural) == 0 {
+		w.writeString(prefix, "msgstr[0]", "", noWrap)
+	}
+}
+
+func (w *poWriter) writeComment(p *Comment, obsolete bool) {
+	prefix := "#| "
+	if obsolete {
+		prefix = "#~| "
+	}
+	writeLines := func(mark, text string) {
+		if text == "" {
+			return
+		}
+		for _, s := range strings.Split(text, "\n") {
+			if s != "" {
+				fmt.Fprintf(w.buf, "%s %s\n", mark, s)
+			} else {
+				fmt.Fprintf(w.buf, "%s\n", mark)
+			}
+		}
+	}
+	writeLines("#", p.TranslatorComment)
+	writeLines("#.", p.ExtractedComment)
+	if a, b := len(p.ReferenceFile), len(p.ReferenceLine); a != 0 && a == b {
+		w.buf.WriteString("#:")
+		column := 2
+		for i := 0; i < len(p.ReferenceFile); i++ {
+			s := fmt.Sprintf(" %s:%d", p.ReferenceFile[i], p.ReferenceLine[i])
+			if w.width > 0 && column > 2 && column+len(s) >= w.width {
+				w.buf.WriteString("\n#:")
+				column = 2
+			}
+			w.buf.WriteString(s)
+			column += len(s)
+		}
+		w.buf.WriteString("\n")
+	}
+	if len(p.Flags) != 0 {
+		fmt.Fprintf(w.buf, "#, %s\n", strings.Join(p.Flags, ", "))
+	}
+	if p.PrevMsgContext != "" {
+		w.writeString(prefix, "msgctxt", p.PrevMsgContext, false)
+	}
+	if p.PrevMsgId != "" {
+		w.writeString(prefix, "msgid", p.PrevMsgId, false)
+	}
+	if p.PrevMsgIdPlural != "" {
+		w.writeString(prefix, "msgid_plural", p.PrevMsgIdPlural, false)
+	}
+}
+
+// writeString writes the keyword and the string, the string is split
+// at the "\n" and wrapped at the page width like GNU's msgcat.
+//
+// See GNU's gettext source code: gettext-tools/src/write-po.c, wrap
+func (w *poWriter) writeString(prefix, keyword, s string, noWrap bool) {
+	w.wrapString(prefix, keyword, s, noWrap, false)
+}
+
+// wrapString is writeString, the header msgstr always starts with
+// an empty first line.
+func (w *poWriter) wrapString(prefix, keyword, s string, noWrap, isHeader bool) {
+	// the page width, allow room for the closing quote
+	width := w.width
+	if width <= 0 || noWrap {
+		width = int(^uint(0) >> 2)
+	}
+	startColAfterBreak := len(prefix) + 1 // the opening quote
+	width -= 1 + startColAfterBreak
+
+	firstLine := true
+	for start := 0; ; {
+		end := strings.IndexByte(s[start:], '\n')
+		if end < 0 {
+			end = len(s)
+		} else {
+			end += start + 1
+		}
+		portion, breaks := escapePoPortion(s[start:end])
+
+		startCol := 0
+		if firstLine {
+			startCol = len(prefix) + len(keyword) + 2 - startColAfterBreak
+		}
+		hasBreak := widthLineBreaks(portion, breaks, width, startCol)
+
+		// use an empty first line, if the string would wrap
+		if firstLine && len(portion) > 0 && (isHeader || end < len(s) || startCol > width || hasBreak) {
+			fmt.Fprintf(w.buf, "%s%s \"\"\n", prefix, keyword)
+			firstLine = false
+			portion, breaks = escapePoPortion(s[start:end])
+			widthLineBreaks(portion, breaks, width, 0)
+		}
+
+		if firstLine {
+			fmt.Fprintf(w.buf, "%s%s \"", prefix, keyword)
+		} else {
+			fmt.Fprintf(w.buf, "%s\"", prefix)
+		}
+		for i := 0; i < len(portion); i++ {
+			if breaks[i] == lineBreakPossible {
+				fmt.Fprintf(w.buf, "\"\n%s\"", prefix)
+			}
+			w.buf.WriteByte(portion[i])
+		}
+		w.buf.WriteString("\"\n")
+		firstLine = false
+
+		if start = end; start >= len(s) {
+			break
+		}
+	}
+}
+
+const (
+	lineBreakUndefined = iota
+	lineBreakProhibited
+	lineBreakPossible
+)
+
+// escapePoPortion escapes the string, returns the escaped string and
+// the line break overrides of every byte.
+func escapePoPortion(s string) (portion []byte, breaks []byte) {
+	const (
+		esc    = "\a\b\f\n\r\t\v"
+		escRep = "abfnrtv"
+	)
+	portion = make([]byte, 0, len(s)+8)
+	breaks = make([]byte, 0, len(s)+8)
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if idx := strings.IndexByte(esc, c); idx >= 0 {
+			portion = append(portion, '\\', escRep[idx])
+			breaks = append(breaks, lineBreakUndefined, lineBreakProhibited)
+		} else if c == '\\' || c == '"' {
+			portion = append(portion, '\\', c)
+			breaks = append(breaks, lineBreakUndefined, lineBreakProhibited)
+		} else {
+			portion = append(portion, c)
+			breaks = append(breaks, lineBreakUndefined)
+		}
+	}
+	// don't break immediately before the "\n" at the end
+	if len(s) > 0 && s[len(s)-1] == '\n' {
+		breaks[len(breaks)-2] = lineBreakProhibited
+	}
+	return
+}
@@ -0,0 +1,375 @@
+// Copyright 2013 ChaiShushan <chaishushan{AT}gmail.com>. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package po
+
+import (
+	"bytes"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestEncoder_roundTrip(t *testing.T) {
+	names, err := filepath.Glob("../../testdata/*.po")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range names {
+		data, err := ioutil.ReadFile(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		f, err := LoadData(data)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if got := f.Data(); !bytes.Equal(got, data) {
+			t.Fatalf("%s: load-then-save is not byte-identical", name)
+		}
+	}
+}
+
+func TestEncoder_reformat(t *testing.T) {
+	names, err := filepath.Glob("../../testdata/mm-ko*.euc-kr.po")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the msgstr of the files are wrapped by msgmerge, which breaks the
+	// URLs at "/", so only the comments are compared
+	comments := func(data []byte) (lines []string) {
+		for _, s := range strings.Split(string(data), "\n") {
+			if strings.HasPrefix(s, "#") {
+				lines = append(lines, s)
+			}
+		}
+		return
+	}
+	for _, name := range names {
+		data, err := ioutil.ReadFile(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		f, err := LoadData(data)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		var buf bytes.Buffer
+		enc := NewEncoder(&buf)
+		enc.Reformat = true
+		if err := enc.Encode(f); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		a, b := comments(data), comments(buf.Bytes())
+		if len(a) != len(b) {
+			t.Fatalf("%s: expect = %d comment lines, got = %d", name, len(a), len(b))
+		}
+		for i := range a {
+			if a[i] != b[i] {
+				t.Fatalf("%s: expect = %q, got = %q", name, a[i], b[i])
+			}
+		}
+	}
+}
+
+func TestEncoder_changed(t *testing.T) {
+	f, err := LoadData([]byte(strings.Replace(testEncoderPoData, "\n", "\r\n", -1)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.Messages[0].MsgStr = "Hallo!"
+	f.Messages[1].MsgStr = strings.Repeat("lang ", 20)
+	f.Messages[2].MsgStr = strings.Repeat("lang ", 20)
+	f.Messages = append(f.Messages, Message{MsgId: "new", MsgStr: "neu\nzeile"})
+
+	expect := strings.Replace(testEncoderPoDataChanged, "\n", "\r\n", -1)
+	if got := string(f.Data()); got != expect {
+		t.Fatalf("expect = %q, got = %q", expect, got)
+	}
+}
+
+func TestEncoder_options(t *testing.T) {
+	f, err := LoadData([]byte(testEncoderPoData))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, v := range []struct {
+		enc    Encoder
+		expect string
+	}{
+		{Encoder{Reformat: true}, testEncoderPoData},
+		{Encoder{Sort: true}, testEncoderPoDataSorted},
+		{Encoder{Width: 30}, testEncoderPoDataWidth30},
+		{Encoder{NoWrap: true}, testEncoderPoDataNoWrap},
+		{Encoder{LineEnding: "\r\n"}, strings.Replace(testEncoderPoData, "\n", "\r\n", -1)},
+	} {
+		var buf bytes.Buffer
+		v.enc.w = &buf
+		if err := v.enc.Encode(f); err != nil {
+			t.Fatalf("%d: %v", i, err)
+		}
+		if got := buf.String(); got != v.expect {
+			t.Fatalf("%d: expect = %q, got = %q", i, v.expect, got)
+		}
+	}
+}
+
+func TestEncoder_new(t *testing.T) {
+	f := &File{
+		Messages: []Message{
+			{
+				Comment: Comment{
+					ReferenceFile: []string{"main.go"},
+					ReferenceLine: []int{10},
+					Flags:         []string{"go-format"},
+				},
+				MsgId:        "%d file",
+				MsgIdPlural:  "%d files",
+				MsgStrPlural: []string{"%d Datei", "%d Dateien"},
+			},
+		},
+	}
+	f.MimeHeader.Language = "de"
+	f.MimeHeader.ContentType = "text/plain; charset=UTF-8"
+
+	expect := `msgid ""
+msgstr ""
+"Project-Id-Version: \n"
+"Report-Msgid-Bugs-To: \n"
+"POT-Creation-Date: \n"
+"PO-Revision-Date: \n"
+"Last-Translator: \n"
+"Language-Team: \n"
+"Language: de\n"
+"MIME-Version: \n"
+"Content-Type: text/plain; charset=UTF-8\n"
+"Content-Transfer-Encoding: \n"
+
+#: main.go:10
+#, go-format
+msgid "%d file"
+msgid_plural "%d files"
+msgstr[0] "%d Datei"
+msgstr[1] "%d Dateien"
+`
+	if got := f.String(); got != expect {
+		t.Fatalf("expect = %q, got = %q", expect, got)
+	}
+}
+
+func TestEncoder_msgmergePrevious(t *testing.T) {
+	f, err := LoadData([]byte(testEncoderMsgmergePoData))
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg := &f.Messages[0]
+	if a, b := msg.PrevMsgId, "The file was deleted.\nUndo?"; a != b {
+		t.Fatalf("expect = %q, got = %q", b, a)
+	}
+	if a, b := msg.PrevMsgIdPlural, "The files were deleted.\nUndo?"; a != b {
+		t.Fatalf("expect = %q, got = %q", b, a)
+	}
+	if a, b := f.Messages[1].PrevMsgIdPlural, "%d days"; a != b {
+		t.Fatalf("expect = %q, got = %q", b, a)
+	}
+
+	var buf bytes.Buffer
+	enc := NewEncoder(&buf)
+	enc.Reformat = true
+	if err := enc.Encode(f); err != nil {
+		t.Fatal(err)
+	}
+	if got := buf.String(); got != testEncoderMsgmergePoData {
+		t.Fatalf("expect = %q, got = %q", testEncoderMsgmergePoData, got)
+	}
+}
+
+// testEncoderMsgmergePoData is the output of "msgmerge --previous".
+const testEncoderMsgmergePoData = `msgid ""
+msgstr ""
+"Language: de\n"
+"Plural-Forms: nplurals=2; plural=(n != 1);\n"
+
+#: main.go:12
+#, fuzzy, go-format
+#| msgid ""
+#| "The file was deleted.\n"
+#| "Undo?"
+#| msgid_plural ""
+#| "The files were deleted.\n"
+#| "Undo?"
+msgid ""
+"%d file was deleted.\n"
+"Undo?"
+msgid_plural ""
+"%d files were deleted.\n"
+"Undo?"
+msgstr[0] ""
+"Die Datei wurde gelöscht.\n"
+"Rückgängig?"
+msgstr[1] ""
+"Die Dateien wurden gelöscht.\n"
+"Rückgängig?"
+
+#: main.go:20
+#, fuzzy, go-format
+#| msgctxt "duration"
+#| msgid "%d day"
+#| msgid_plural "%d days"
+msgctxt "duration"
+msgid "%d day left"
+msgid_plural "%d days left"
+msgstr[0] "%d Tag"
+msgstr[1] "%d Tage"
+
+#~| msgid "Close"
+#~| msgid_plural "Close all"
+#~ msgid "Close %d"
+#~ msgid_plural "Close all %d"
+#~ msgstr[0] "Schließen"
+#~ msgstr[1] "Alle schließen"
+`
+
+const testEncoderPoData = `msgid ""
+msgstr ""
+"Language: de\n"
+
+#: b.go:1
+msgid "b"
+msgstr "B"
+
+#: a.go:1
+msgid "a"
+msgstr ""
+"one two three four five six seven eight nine ten eleven twelve thirteen "
+"fourteen"
+
+#, no-wrap
+msgid "c"
+msgstr "one two three four five six seven eight nine ten eleven twelve thirteen fourteen"
+`
+
+const testEncoderPoDataSorted = `msgid ""
+msgstr ""
+"Language: de\n"
+
+#: a.go:1
+msgid "a"
+msgstr ""
+"one two three four five six seven eight nine ten eleven twelve thirteen "
+"fourteen"
+
+#: b.go:1
+msgid "b"
+msgstr "B"
+
+#, no-wrap
+msgid "c"
+msgstr "one two three four five six seven eight nine ten eleven twelve thirteen fourteen"
+`
+
+const testEncoderPoDataWidth30 = `msgid ""
+msgstr ""
+"Language: de\n"
+
+#: b.go:1
+msgid "b"
+msgstr "B"
+
+#: a.go:1
+msgid "a"
+msgstr ""
+"one two three four five six "
+"seven eight nine ten eleven "
+"twelve thirteen fourteen"
+
+#, no-wrap
+msgid "c"
+msgstr "one two three four five six seven eight nine ten eleven twelve thirteen fourteen"
+`
+
+const testEncoderPoDataNoWrap = `msgid ""
+msgstr ""
+"Language: de\n"
+
+#: b.go:1
+msgid "b"
+msgstr "B"
+
+#: a.go:1
+msgid "a"
+msgstr "one two three four five six seven eight nine ten eleven twelve thirteen fourteen"
+
+#, no-wrap
+msgid "c"
+msgstr "one two three four five six seven eight nine ten eleven twelve thirteen fourteen"
+`
+
+const testEncoderPoDataChanged = `msgid ""
+msgstr ""
+"Language: de\n"
+
+#: b.go:1
+msgid "b"
+msgstr "Hallo!"
+
+#: a.go:1
+msgid "a"
+msgstr ""
+"lang lang lang lang lang lang lang lang lang lang lang lang lang lang lang "
+"lang lang lang lang lang "
+
+#, no-wrap
+msgid "c"
+msgstr "lang lang lang lang lang lang lang lang lang lang lang lang lang lang lang lang lang lang lang lang "
+
+msgid "new"
+msgstr ""
+"neu\n"
+"zeile"
+`
+
+func TestEncoder_charset(t *testing.T) {
+	f, err := Load("../../testdata/mm-ko.euc-kr.po")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var found bool
+	for _, msg := range f.Messages {
+		if msg.MsgId == "OUTPUT" {
+			found = msg.MsgStr == "출력"
+		}
+	}
+	if !found {
+		t.Fatalf("the msgstr of %q is not decoded", "OUTPUT")
+	}
+
+	var buf bytes.Buffer
+	enc := NewEncoder(&buf)
+	enc.Charset = "UTF-8"
+	if err := enc.Encode(f); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Contains(buf.Bytes(), []byte(`msgstr "출력"`)) {
+		t.Fatalf("the file is not converted to UTF-8")
+	}
+	g, err := LoadData(buf.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := g.MimeHeader.ContentType; s != "text/plain; charset=UTF-8" {
+		t.Fatalf("bad Content-Type: %q", s)
+	}
+	for i := 0; i < len(f.Messages); i++ {
+		if a, b := &f.Messages[i], &g.Messages[i]; a.MsgId != b.MsgId || a.MsgStr != b.MsgStr {
+			t.Fatalf("%d: expect = %q, got = %q", i, a.MsgStr, b.MsgStr)
+		}
+	}
+
+	f.Messages[0].MsgStr = "☃"
+	if err := NewEncoder(&buf).Encode(f); err == nil {
+		t.Fatalf("expect an error of the char which EUC-KR doesn't have")
+	}
+}
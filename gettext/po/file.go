@@ -6,10 +6,7 @@ package po
 
 import (
 	"bytes"
-	"fmt"
-	"io"
 	"io/ioutil"
-	"sort"
 )
 
 // File represents an PO File.
@@ -18,6 +15,15 @@ import (
 type File struct {
 	MimeHeader Header
 	Messages   []Message
+
+	raw *rawFile // the loaded file
+}
+
+// rawFile is the source text of the loaded file, which is not
+// a part of any entry.
+type rawFile struct {
+	tail       string // the blank lines and comments at the end of file
+	lineEnding string
 }
 
 // Load loads a named po file.
@@ -30,43 +36,29 @@ func Load(name string) (*File, error) {
 }
 
 // LoadData loads po file format data.
+//
+// It stops at the first syntax error, which is a *SyntaxError,
+// use Decoder to get all the syntax errors.
 func LoadData(data []byte) (*File, error) {
-	r := newLineReader(string(data))
-	var file File
-	for {
-		var msg Message
-		if err := msg.readPoEntry(r); err != nil {
-			if err == io.EOF {
-				return &file, nil
-			}
-			return nil, err
-		}
-		if msg.MsgId == "" {
-			file.MimeHeader.parseHeader(&msg)
-			continue
-		}
-		file.Messages = append(file.Messages, msg)
-	}
-	return &file, nil
+	return NewDecoder(bytes.NewReader(data)).decodeFile()
 }
 
 // Save saves a po file.
 func (f *File) Save(name string) error {
-	return ioutil.WriteFile(name, []byte(f.String()), 0666)
+	var buf bytes.Buffer
+	if err := NewEncoder(&buf).Encode(f); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(name, buf.Bytes(), 0666)
 }
 
 // Save returns a po file format data.
+//
+// The messages keep their order, and the unchanged messages of
+// a loaded file are written as they were loaded.
 func (f *File) Data() []byte {
-	// sort the massge as ReferenceFile/ReferenceLine field
-	var messages []Message
-	messages = append(messages, f.Messages...)
-	sort.Sort(byMessages(messages))
-
 	var buf bytes.Buffer
-	fmt.Fprintf(&buf, "%s\n", f.MimeHeader.String())
-	for i := 0; i < len(messages); i++ {
-		fmt.Fprintf(&buf, "%s\n", messages[i].String())
-	}
+	NewEncoder(&buf).Encode(f)
 	return buf.Bytes()
 }
 
@@ -7,7 +7,11 @@ package po
 import (
 	"bytes"
 	"fmt"
+	"reflect"
+	"sort"
 	"strings"
+
+	"github.com/faxal/gettext-go/gettext/charset"
 )
 
 // Header is the initial comments "SOME DESCRIPTIVE TITLE", "YEAR"
@@ -29,6 +33,57 @@ type Header struct {
 	PluralForms             string // Plural-Forms: nplurals=2; plural=n == 1 ? 0 : 1;
 	XGenerator              string // X-Generator: Poedit 1.5.5
 	UnknowFields            map[string]string
+
+	fieldOrder []string   // the field names of the loaded header, in order
+	raw        *rawHeader // the loaded header entry
+}
+
+// rawHeader is the source text of the loaded header entry,
+// and the header it was decoded to.
+type rawHeader struct {
+	text   string
+	header Header
+}
+
+// headerFields are the known header fields, in GNU's order.
+var headerFields = []struct {
+	name     string
+	value    func(p *Header) *string
+	template bool // written even if it's empty, like xgettext
+}{
+	{"Project-Id-Version", func(p *Header) *string { return &p.ProjectIdVersion }, true},
+	{"Report-Msgid-Bugs-To", func(p *Header) *string { return &p.ReportMsgidBugsTo }, true},
+	{"POT-Creation-Date", func(p *Header) *string { return &p.POTCreationDate }, true},
+	{"PO-Revision-Date", func(p *Header) *string { return &p.PORevisionDate }, true},
+	{"Last-Translator", func(p *Header) *string { return &p.LastTranslator }, true},
+	{"Language-Team", func(p *Header) *string { return &p.LanguageTeam }, true},
+	{"Language", func(p *Header) *string { return &p.Language }, true},
+	{"MIME-Version", func(p *Header) *string { return &p.MimeVersion }, true},
+	{"Content-Type", func(p *Header) *string { return &p.ContentType }, true},
+	{"Content-Transfer-Encoding", func(p *Header) *string { return &p.ContentTransferEncoding }, true},
+	{"Plural-Forms", func(p *Header) *string { return &p.PluralForms }, false},
+	{"X-Generator", func(p *Header) *string { return &p.XGenerator }, false},
+}
+
+// NewTemplateHeader returns the header of a new po template file, with
+// the placeholders of GNU xgettext. The POT-Creation-Date is not set.
+func NewTemplateHeader() Header {
+	return Header{
+		Comment: Comment{
+			TranslatorComment: "SOME DESCRIPTIVE TITLE.\n" +
+				"Copyright (C) YEAR THE PACKAGE'S COPYRIGHT HOLDER\n" +
+				"This file is distributed under the same license as the PACKAGE package.\n" +
+				"FIRST AUTHOR <EMAIL@ADDRESS>, YEAR.\n",
+			Flags: []string{"fuzzy"},
+		},
+		ProjectIdVersion:        "PACKAGE VERSION",
+		PORevisionDate:          "YEAR-MO-DA HO:MI+ZONE",
+		LastTranslator:          "FULL NAME <EMAIL@ADDRESS>",
+		LanguageTeam:            "LANGUAGE <LL@li.org>",
+		MimeVersion:             "1.0",
+		ContentType:             "text/plain; charset=UTF-8",
+		ContentTransferEncoding: "8bit",
+	}
 }
 
 func (p *Header) parseHeader(msg *Message) {
@@ -43,64 +98,102 @@ func (p *Header) parseHeader(msg *Message) {
 		}
 		key := strings.TrimSpace(lines[i][:idx])
 		val := strings.TrimSpace(lines[i][idx+1:])
-		switch strings.ToUpper(key) {
-		case strings.ToUpper("Project-Id-Version"):
-			p.ProjectIdVersion = val
-		case strings.ToUpper("Report-Msgid-Bugs-To"):
-			p.ReportMsgidBugsTo = val
-		case strings.ToUpper("POT-Creation-Date"):
-			p.POTCreationDate = val
-		case strings.ToUpper("PO-Revision-Date"):
-			p.PORevisionDate = val
-		case strings.ToUpper("Last-Translator"):
-			p.LastTranslator = val
-		case strings.ToUpper("Language-Team"):
-			p.LanguageTeam = val
-		case strings.ToUpper("Language"):
-			p.Language = val
-		case strings.ToUpper("MIME-Version"):
-			p.MimeVersion = val
-		case strings.ToUpper("Content-Type"):
-			p.ContentType = val
-		case strings.ToUpper("Content-Transfer-Encoding"):
-			p.ContentTransferEncoding = val
-		case strings.ToUpper("Plural-Forms"):
-			p.PluralForms = val
-		case strings.ToUpper("X-Generator"):
-			p.XGenerator = val
-		default:
-			if p.UnknowFields == nil {
-				p.UnknowFields = make(map[string]string)
-			}
-			p.UnknowFields[key] = val
+		p.fieldOrder = append(p.fieldOrder, key)
+		if v := p.field(key); v != nil {
+			*v = val
+			continue
+		}
+		if p.UnknowFields == nil {
+			p.UnknowFields = make(map[string]string)
 		}
+		p.UnknowFields[key] = val
 	}
 	p.Comment = msg.Comment
 }
 
-// String returns the po format header string.
-func (p Header) String() string {
+// Charset returns the charset of the Content-Type field, like "UTF-8".
+func (p *Header) Charset() string {
+	return charset.ContentTypeCharset(p.ContentType)
+}
+
+// field returns the known field of the name, or nil.
+func (p *Header) field(name string) *string {
+	for _, f := range headerFields {
+		if strings.EqualFold(f.name, name) {
+			return f.value(p)
+		}
+	}
+	return nil
+}
+
+// clone returns a deep copy of the header, without the raw text.
+func (p *Header) clone() Header {
+	q := *p
+	q.Comment = p.Comment.clone()
+	q.raw = nil
+	if p.UnknowFields != nil {
+		q.UnknowFields = make(map[string]string)
+		for k, v := range p.UnknowFields {
+			q.UnknowFields[k] = v
+		}
+	}
+	q.fieldOrder = append([]string(nil), p.fieldOrder...)
+	return q
+}
+
+// isChanged reports whether the loaded header is changed.
+func (p *Header) isChanged() bool {
+	if p.raw == nil {
+		return true
+	}
+	q := p.clone()
+	return !reflect.DeepEqual(&q, &p.raw.header)
+}
+
+// msgStr returns the header entry's msgstr.
+//
+// The fields of the loaded header keep their order, and the new
+// fields are appended in GNU's order.
+func (p *Header) msgStr() string {
 	var buf bytes.Buffer
-	fmt.Fprintf(&buf, "%s", p.Comment.String())
-	fmt.Fprintf(&buf, `msgid ""`+"\n")
-	fmt.Fprintf(&buf, `msgstr ""`+"\n")
-	fmt.Fprintf(&buf, `"%s: %s\n"`+"\n", "Project-Id-Version", p.ProjectIdVersion)
-	fmt.Fprintf(&buf, `"%s: %s\n"`+"\n", "Report-Msgid-Bugs-To", p.ReportMsgidBugsTo)
-	fmt.Fprintf(&buf, `"%s: %s\n"`+"\n", "POT-Creation-Date", p.POTCreationDate)
-	fmt.Fprintf(&buf, `"%s: %s\n"`+"\n", "PO-Revision-Date", p.PORevisionDate)
-	fmt.Fprintf(&buf, `"%s: %s\n"`+"\n", "Last-Translator", p.LastTranslator)
-	fmt.Fprintf(&buf, `"%s: %s\n"`+"\n", "Language-Team", p.LanguageTeam)
-	fmt.Fprintf(&buf, `"%s: %s\n"`+"\n", "Language", p.Language)
-	if p.MimeVersion != "" {
-		fmt.Fprintf(&buf, `"%s: %s\n"`+"\n", "MIME-Version", p.MimeVersion)
+	done := make(map[string]bool)
+	for _, key := range p.fieldOrder {
+		name := strings.ToUpper(key)
+		if done[name] {
+			continue
+		}
+		if v := p.field(key); v != nil {
+			fmt.Fprintf(&buf, "%s: %s\n", key, *v)
+		} else if v, ok := p.UnknowFields[key]; ok {
+			fmt.Fprintf(&buf, "%s: %s\n", key, v)
+		} else {
+			continue
+		}
+		done[name] = true
 	}
-	fmt.Fprintf(&buf, `"%s: %s\n"`+"\n", "Content-Type", p.ContentType)
-	fmt.Fprintf(&buf, `"%s: %s\n"`+"\n", "Content-Transfer-Encoding", p.ContentTransferEncoding)
-	if p.XGenerator != "" {
-		fmt.Fprintf(&buf, `"%s: %s\n"`+"\n", "X-Generator", p.XGenerator)
+	for _, f := range headerFields {
+		v := *f.value(p)
+		if done[strings.ToUpper(f.name)] || (v == "" && !(f.template && p.fieldOrder == nil)) {
+			continue
+		}
+		fmt.Fprintf(&buf, "%s: %s\n", f.name, v)
 	}
-	for k, v := range p.UnknowFields {
-		fmt.Fprintf(&buf, `"%s: %s\n"`+"\n", k, v)
+	var keys []string
+	for k := range p.UnknowFields {
+		if !done[strings.ToUpper(k)] {
+			keys = append(keys, k)
+		}
 	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		fmt.Fprintf(&buf, "%s: %s\n", k, p.UnknowFields[k])
+	}
+	return buf.String()
+}
+
+// String returns the po format header string.
+func (p Header) String() string {
+	var buf bytes.Buffer
+	newPoWriter(&buf, defaultWidth).writeHeader(&p)
 	return buf.String()
 }
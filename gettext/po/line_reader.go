@@ -5,58 +5,125 @@
 package po
 
 import (
+	"bufio"
 	"io"
 	"strings"
+
+	"github.com/faxal/gettext-go/gettext/charset"
 )
 
+// lineReader reads the lines of a po file one by one,
+// and can unread the last read line.
 type lineReader struct {
-	lines []string
-	pos   int
+	r   *bufio.Reader
+	err error // the read error, returned after all lines
+
+	pos     int    // index of the next line
+	next    string // the next line, if hasNext
+	nextRaw string // the next line with the line ending
+	hasNext bool
+	last    string // the last read line, for unreadLine
+	lastRaw string
+
+	raw        []string // the raw read lines, since the last takeRaw
+	lineEnding string   // the line ending of the first line
+
+	enc *charset.Encoding // the charset of the next lines, nil means UTF-8
 }
 
-func newLineReader(data string) *lineReader {
-	data = strings.Replace(data, "\r", "", -1)
-	lines := strings.Split(data, "\n")
-	return &lineReader{lines: lines}
+func newLineReader(r io.Reader) *lineReader {
+	return &lineReader{r: bufio.NewReader(r)}
 }
 
-func (r *lineReader) skipBlankLine() error {
-	for ; r.pos < len(r.lines); r.pos++ {
-		if strings.TrimSpace(r.lines[r.pos]) != "" {
-			break
+// fill reads the next line from the underlying reader.
+func (r *lineReader) fill() error {
+	if r.hasNext {
+		return nil
+	}
+	if r.err != nil {
+		return r.err
+	}
+	s, err := r.r.ReadString('\n')
+	if err != nil {
+		r.err = err
+		if s == "" {
+			return err
 		}
 	}
-	if r.pos >= len(r.lines) {
-		return io.EOF
+	if r.enc != nil {
+		s = r.enc.DecodeString(s)
+	}
+	raw := s
+	s = strings.TrimSuffix(s, "\n")
+	if r.lineEnding == "" && len(s) != len(raw) {
+		r.lineEnding = "\n"
+		if strings.HasSuffix(s, "\r") {
+			r.lineEnding = "\r\n"
+		}
 	}
+	s = strings.TrimSuffix(s, "\r")
+	r.next, r.nextRaw, r.hasNext = s, raw, true
 	return nil
 }
 
+func (r *lineReader) skipBlankLine() error {
+	for {
+		s, _, err := r.currentLine()
+		if err != nil {
+			return err
+		}
+		if strings.TrimSpace(s) != "" {
+			return nil
+		}
+		r.readLine()
+	}
+}
+
 func (r *lineReader) currentPos() int {
 	return r.pos
 }
 
 func (r *lineReader) currentLine() (s string, pos int, err error) {
-	if r.pos >= len(r.lines) {
-		err = io.EOF
+	if err = r.fill(); err != nil {
 		return
 	}
-	s, pos = r.lines[r.pos], r.pos
+	s, pos = r.next, r.pos
 	return
 }
 
 func (r *lineReader) readLine() (s string, pos int, err error) {
-	if r.pos >= len(r.lines) {
-		err = io.EOF
+	if s, pos, err = r.currentLine(); err != nil {
 		return
 	}
-	s, pos = r.lines[r.pos], r.pos
+	r.last, r.lastRaw, r.hasNext = s, r.nextRaw, false
+	r.raw = append(r.raw, r.lastRaw)
 	r.pos++
 	return
 }
 
+// unreadLine unreads the last read line, only one line can be unread.
 func (r *lineReader) unreadLine() {
-	if r.pos >= 0 {
-		r.pos--
+	if r.hasNext || r.pos == 0 {
+		return
+	}
+	r.next, r.nextRaw, r.hasNext = r.last, r.lastRaw, true
+	if len(r.raw) != 0 {
+		r.raw = r.raw[:len(r.raw)-1]
 	}
+	r.pos--
+}
+
+// setEncoding decodes the next lines by the charset.
+func (r *lineReader) setEncoding(enc *charset.Encoding) {
+	r.enc = enc
+	if r.hasNext {
+		r.next, r.nextRaw = enc.DecodeString(r.next), enc.DecodeString(r.nextRaw)
+	}
+}
+
+// takeRaw returns the raw text of the lines read since the last call.
+func (r *lineReader) takeRaw() string {
+	s := strings.Join(r.raw, "")
+	r.raw = r.raw[:0]
+	return s
 }
@@ -0,0 +1,145 @@
+// Copyright 2013 ChaiShushan <chaishushan{AT}gmail.com>. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package po
+
+import (
+	"unicode"
+	"unicode/utf8"
+)
+
+// widthLineBreaks computes the line breaks of s, so that every line fits
+// in width columns, the first line starts at startCol.
+//
+// The breaks holds the overrides of every byte of s when called, and
+// holds lineBreakPossible at the chosen line breaks when returned.
+// Returns whether any line break is chosen.
+//
+// It is a simplified version of GNU's ulc_width_linebreaks, the line
+// break opportunities are a subset of the Unicode line breaking algorithm:
+// after spaces and hyphens, and around the ideographs.
+//
+// See GNU's gnulib source code: lib/unilbrk/u8-width-linebreaks.c
+func widthLineBreaks(s []byte, breaks []byte, width, startCol int) bool {
+	var (
+		lastBreak   = -1 // the last break opportunity
+		lastColumn  = startCol
+		pieceWidth  = 0
+		prevClass   = lbNone
+		hasBreak    = false
+		insertBreak = func() {
+			breaks[lastBreak] = lineBreakPossible
+			lastColumn = 0
+			hasBreak = true
+		}
+	)
+	for i := 0; i < len(s); {
+		r, size := utf8.DecodeRune(s[i:])
+		class := lineBreakClass(r)
+
+		possible := prevClass != lbNone && isLineBreakOpportunity(prevClass, class)
+		if breaks[i] != lineBreakUndefined {
+			possible = breaks[i] == lineBreakPossible
+		}
+		if possible {
+			// an atomic piece of text ends here
+			if lastBreak >= 0 && lastColumn+pieceWidth > width {
+				insertBreak()
+			}
+			lastBreak = i
+			lastColumn += pieceWidth
+			pieceWidth = 0
+		}
+		for j := i; j < i+size; j++ {
+			breaks[j] = lineBreakProhibited
+		}
+		pieceWidth += runeWidth(r)
+		prevClass = class
+		i += size
+	}
+	// the last atomic piece of text ends here
+	if lastBreak >= 0 && lastColumn+pieceWidth > width {
+		insertBreak()
+	}
+	return hasBreak
+}
+
+// line break classes, a subset of the Unicode line breaking classes.
+const (
+	lbNone = iota
+	lbAL   // alphabetic and others
+	lbSP   // space
+	lbHY   // hyphen
+	lbID   // ideographic
+	lbOP   // opening punctuation
+	lbCL   // closing punctuation
+)
+
+func lineBreakClass(r rune) int {
+	switch r {
+	case ' ':
+		return lbSP
+	case '-':
+		return lbHY
+	case '（', '「', '『', '【', '《', '〈', '〔', '［', '｛':
+		return lbOP
+	case '，', '。', '、', '：', '；', '！', '？', '）', '」', '』', '】', '》', '〉', '〕', '］', '｝', '．', '・', 'ー', '々':
+		return lbCL
+	}
+	if isWideRune(r) {
+		return lbID
+	}
+	return lbAL
+}
+
+// isLineBreakOpportunity reports whether a line can break between
+// the two classes.
+func isLineBreakOpportunity(prev, next int) bool {
+	switch {
+	case next == lbSP || next == lbCL || next == lbHY:
+		return false // no break before spaces and closing punctuations
+	case prev == lbOP:
+		return false // no break after opening punctuations
+	case prev == lbSP:
+		return true // break after spaces
+	case prev == lbHY:
+		return next == lbAL || next == lbID
+	case prev == lbID || next == lbID:
+		return true // break around ideographs
+	}
+	return false
+}
+
+// runeWidth returns the columns of the rune.
+func runeWidth(r rune) int {
+	switch {
+	case r < 0x20 || (0x7f <= r && r < 0xa0):
+		return 0
+	case unicode.In(r, unicode.Mn, unicode.Me, unicode.Cf):
+		return 0
+	case isWideRune(r):
+		return 2
+	}
+	return 1
+}
+
+// isWideRune reports whether the rune is an East Asian wide character.
+func isWideRune(r rune) bool {
+	switch {
+	case 0x1100 <= r && r <= 0x115F,
+		0x2E80 <= r && r <= 0x303E,
+		0x3041 <= r && r <= 0x33FF,
+		0x3400 <= r && r <= 0x4DBF,
+		0x4E00 <= r && r <= 0x9FFF,
+		0xA000 <= r && r <= 0xA4CF,
+		0xAC00 <= r && r <= 0xD7A3,
+		0xF900 <= r && r <= 0xFAFF,
+		0xFE30 <= r && r <= 0xFE4F,
+		0xFF00 <= r && r <= 0xFF60,
+		0xFFE0 <= r && r <= 0xFFE6,
+		0x20000 <= r && r <= 0x3FFFD:
+		return true
+	}
+	return false
+}
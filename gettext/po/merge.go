@@ -0,0 +1,255 @@
+// Copyright 2013 ChaiShushan <chaishushan{AT}gmail.com>. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package po
+
+import (
+	"strings"
+)
+
+// defaultFuzzyThreshold is the minimum similarity of GNU's msgmerge.
+const defaultFuzzyThreshold = 0.6
+
+// MergeOptions are the options of Merge.
+type MergeOptions struct {
+	NoFuzzyMatching bool    // don't translate the new messages by the similar messages
+	FuzzyThreshold  float64 // minimum similarity of the fuzzy matching, 0 means 0.6 like msgmerge
+}
+
+// Merge updates the translations of def to the messages of the template
+// ref, like GNU's msgmerge.
+//
+// The messages are in the order of ref:
+//	- the message of def with the same msgctxt and msgid keeps its
+//	  translation, translator comments and fuzzy flag
+//	- a new message is translated by the most similar message of def,
+//	  it's marked fuzzy and has the previous msgctxt and msgid of def
+//	- the other new messages are untranslated
+// The references, extracted comments and format flags are taken from ref.
+// The translated messages of def which are not in ref become obsolete,
+// and follow the messages of ref.
+//
+// The unchanged messages of a loaded def are written as they were loaded.
+func Merge(def, ref *File, opts *MergeOptions) *File {
+	if opts == nil {
+		opts = &MergeOptions{}
+	}
+	threshold := opts.FuzzyThreshold
+	if threshold <= 0 {
+		threshold = defaultFuzzyThreshold
+	}
+
+	f := &File{
+		MimeHeader: def.MimeHeader.clone(),
+		raw:        def.raw,
+	}
+	f.MimeHeader.raw = def.MimeHeader.raw
+	if ref.MimeHeader.POTCreationDate != "" {
+		f.MimeHeader.POTCreationDate = ref.MimeHeader.POTCreationDate
+	}
+
+	// the index of def messages, the active one is preferred
+	index := make(map[string]int)
+	for i := 0; i < len(def.Messages); i++ {
+		key := def.Messages[i].MsgContext + "\x04" + def.Messages[i].MsgId
+		if j, ok := index[key]; !ok || (def.Messages[j].Obsolete && !def.Messages[i].Obsolete) {
+			index[key] = i
+		}
+	}
+	used := make([]bool, len(def.Messages))
+	for i := 0; i < len(ref.Messages); i++ {
+		r := &ref.Messages[i]
+		if r.Obsolete {
+			continue
+		}
+		if j, ok := index[r.MsgContext+"\x04"+r.MsgId]; ok {
+			used[j] = true
+			f.Messages = append(f.Messages, mergeMessage(&def.Messages[j], r))
+			continue
+		}
+		if !opts.NoFuzzyMatching {
+			if j := fuzzySearch(def.Messages, r, threshold); j >= 0 {
+				used[j] = true
+				f.Messages = append(f.Messages, fuzzyMessage(&def.Messages[j], r))
+				continue
+			}
+		}
+		msg := r.clone()
+		msg.Obsolete = false
+		msg.SetFuzzy(false)
+		msg.PrevMsgContext, msg.PrevMsgId, msg.PrevMsgIdPlural = "", "", ""
+		f.Messages = append(f.Messages, msg)
+	}
+	for i := 0; i < len(def.Messages); i++ {
+		msg := def.Messages[i]
+		if used[i] || !(msg.Obsolete || msg.isTranslated()) {
+			continue
+		}
+		if !msg.Obsolete {
+			msg.Obsolete = true
+			msg.ExtractedComment = ""
+			msg.ReferenceFile, msg.ReferenceLine = nil, nil
+		}
+		f.Messages = append(f.Messages, msg)
+	}
+	return f
+}
+
+// mergeMessage returns the message of def with the comments of ref.
+func mergeMessage(def, ref *Message) Message {
+	msg := *def // keep the raw entry
+	msg.Obsolete = false
+	msg.ExtractedComment = ref.ExtractedComment
+	msg.ReferenceFile = cloneStrings(ref.ReferenceFile)
+	msg.ReferenceLine = append([]int(nil), ref.ReferenceLine...)
+	msg.Flags = mergeFlags(def.Flags, ref.Flags)
+	if def.MsgIdPlural != ref.MsgIdPlural {
+		msg.setMsgIdPlural(ref.MsgIdPlural)
+		if def.isTranslated() {
+			msg.SetFuzzy(true)
+			msg.PrevMsgContext, msg.PrevMsgId, msg.PrevMsgIdPlural = def.MsgContext, def.MsgId, def.MsgIdPlural
+		}
+	}
+	if !msg.GetFuzzy() {
+		msg.PrevMsgContext, msg.PrevMsgId, msg.PrevMsgIdPlural = "", "", ""
+	}
+	return msg
+}
+
+// fuzzyMessage returns the message of ref, which is translated by the
+// similar message of def.
+func fuzzyMessage(def, ref *Message) Message {
+	msg := ref.clone()
+	msg.Obsolete = false
+	msg.TranslatorComment = def.TranslatorComment
+	msg.MsgIdPlural = def.MsgIdPlural
+	msg.MsgStr = def.MsgStr
+	msg.MsgStrPlural = cloneStrings(def.MsgStrPlural)
+	msg.setMsgIdPlural(ref.MsgIdPlural)
+	msg.PrevMsgContext, msg.PrevMsgId, msg.PrevMsgIdPlural = def.MsgContext, def.MsgId, def.MsgIdPlural
+	msg.SetFuzzy(true)
+	return msg
+}
+
+// mergeFlags returns the flags of def like "fuzzy", and the flags of ref
+// like "go-format". The format flags of def are dropped.
+func mergeFlags(flags, refFlags []string) []string {
+	var s []string
+	for _, flag := range flags {
+		if strings.HasSuffix(flag, "-format") || (flag != "fuzzy" && containsString(refFlags, flag)) {
+			continue
+		}
+		s = append(s, flag)
+	}
+	for _, flag := range refFlags {
+		if flag != "fuzzy" {
+			s = append(s, flag)
+		}
+	}
+	if strings.Join(s, ",") == strings.Join(flags, ",") {
+		return flags // keep the loaded message unchanged
+	}
+	return s
+}
+
+func containsString(ss []string, s string) bool {
+	for _, v := range ss {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// setMsgIdPlural sets the msgid_plural, and converts the msgstr
+// between the singular and plural forms.
+func (p *Message) setMsgIdPlural(s string) {
+	switch {
+	case p.MsgIdPlural == "" && s != "":
+		if p.MsgStr != "" {
+			p.MsgStrPlural = []string{p.MsgStr}
+		}
+		p.MsgStr = ""
+	case p.MsgIdPlural != "" && s == "":
+		if len(p.MsgStrPlural) != 0 {
+			p.MsgStr = p.MsgStrPlural[0]
+		}
+		p.MsgStrPlural = nil
+	}
+	p.MsgIdPlural = s
+}
+
+// isTranslated reports whether all the msgstr of the message are not empty.
+func (p *Message) isTranslated() bool {
+	if p.MsgIdPlural == "" {
+		return p.MsgStr != ""
+	}
+	if len(p.MsgStrPlural) == 0 {
+		return false
+	}
+	for _, s := range p.MsgStrPlural {
+		if s == "" {
+			return false
+		}
+	}
+	return true
+}
+
+// fuzzySearch returns the index of the translated message which is most
+// similar to msg, or -1 if there is no similar message.
+//
+// The msgids are compared, and the message of another msgctxt is a little
+// less similar, like GNU's msgmerge.
+func fuzzySearch(messages []Message, msg *Message, threshold float64) int {
+	best, bestScore := -1, threshold
+	id := []rune(msg.MsgId)
+	for i := 0; i < len(messages); i++ {
+		m := &messages[i]
+		if m.MsgId == "" || !m.isTranslated() {
+			continue
+		}
+		score := similarity(id, []rune(m.MsgId), bestScore)
+		if m.MsgContext != msg.MsgContext {
+			score *= 0.99
+		}
+		if score > bestScore || (score == bestScore && best < 0) {
+			best, bestScore = i, score
+		}
+	}
+	return best
+}
+
+// similarity returns the similarity of the strings, which is in [0, 1],
+// like the fstrcmp of GNU's gettext:
+//	2 * len(longest common subsequence) / (len(a) + len(b))
+// It returns 0 if the similarity is certainly less than min.
+func similarity(a, b []rune, min float64) float64 {
+	n := len(a) + len(b)
+	if n == 0 {
+		return 1
+	}
+	short := len(a)
+	if len(b) < short {
+		short = len(b)
+	}
+	if float64(2*short)/float64(n) < min {
+		return 0
+	}
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for i := 1; i <= len(a); i++ {
+		for j := 1; j <= len(b); j++ {
+			switch {
+			case a[i-1] == b[j-1]:
+				cur[j] = prev[j-1] + 1
+			case prev[j] >= cur[j-1]:
+				cur[j] = prev[j]
+			default:
+				cur[j] = cur[j-1]
+			}
+		}
+		prev, cur = cur, prev
+	}
+	return float64(2*prev[len(b)]) / float64(n)
+}
@@ -0,0 +1,173 @@
+// Copyright 2013 ChaiShushan <chaishushan{AT}gmail.com>. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package po
+
+import (
+	"math"
+	"testing"
+)
+
+func TestMerge(t *testing.T) {
+	def, err := LoadData([]byte(testMergeDefData))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ref, err := LoadData([]byte(testMergeRefData))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := Merge(def, ref, nil).String(); got != testMergeExpectData {
+		t.Fatalf("expect = %q, got = %q", testMergeExpectData, got)
+	}
+	if got := Merge(def, def, nil).String(); got != testMergeDefData {
+		t.Fatalf("expect = %q, got = %q", testMergeDefData, got)
+	}
+
+	f := Merge(def, ref, &MergeOptions{NoFuzzyMatching: true})
+	for _, msg := range f.Messages {
+		if msg.PrevMsgId != "" || (msg.MsgId == "Open the files" && msg.MsgStr != "") {
+			t.Fatalf("%q: unexpected fuzzy matching", msg.MsgId)
+		}
+	}
+}
+
+func TestSimilarity(t *testing.T) {
+	for i, v := range []struct {
+		a, b   string
+		expect float64
+	}{
+		{"", "", 1},
+		{"abc", "abc", 1},
+		{"abc", "", 0},
+		{"abc", "xyz", 0},
+		{"Hello, world!", "Hello, world", 24.0 / 25.0},
+		{"你好, 世界!", "你好, 世界", 12.0 / 13.0},
+	} {
+		got := similarity([]rune(v.a), []rune(v.b), 0)
+		if math.Abs(got-v.expect) > 1e-9 {
+			t.Fatalf("%d: expect = %v, got = %v", i, v.expect, got)
+		}
+	}
+}
+
+func TestComment_SetFuzzy(t *testing.T) {
+	var p Comment
+	p.Flags = []string{"go-format"}
+	if p.SetFuzzy(true); !p.GetFuzzy() || p.Flags[0] != "fuzzy" || len(p.Flags) != 2 {
+		t.Fatalf("bad flags: %v", p.Flags)
+	}
+	if p.SetFuzzy(false); p.GetFuzzy() || len(p.Flags) != 1 {
+		t.Fatalf("bad flags: %v", p.Flags)
+	}
+}
+
+const testMergeDefData = `# German translation.
+msgid ""
+msgstr ""
+"Project-Id-Version: hello\n"
+"POT-Creation-Date: 2013-12-12 20:03+0000\n"
+"Language: de\n"
+"Content-Type: text/plain; charset=UTF-8\n"
+
+# keep the translator comment
+#: hello.go:10
+msgctxt "main.main"
+msgid "Hello, world!"
+msgstr "Hallo, Welt!"
+
+#: hello.go:20
+#, fuzzy, c-format
+msgid "%d file"
+msgid_plural "%d files"
+msgstr[0] "%d Datei"
+msgstr[1] "%d Dateien"
+
+#: hello.go:30
+msgid "Open the file"
+msgstr "Datei öffnen"
+
+#: hello.go:40
+msgid "Removed"
+msgstr ""
+
+#: hello.go:50
+msgid "Removed and translated"
+msgstr "Entfernt"
+
+#~ msgid "Old"
+#~ msgstr "Alt"
+`
+
+const testMergeRefData = `msgid ""
+msgstr ""
+"Project-Id-Version: PACKAGE VERSION\n"
+"POT-Creation-Date: 2014-01-01 10:00+0000\n"
+"Content-Type: text/plain; charset=UTF-8\n"
+
+#. TRANSLATORS: the greeting
+#: hello.go:11
+msgctxt "main.main"
+msgid "Hello, world!"
+msgstr ""
+
+#: hello.go:21
+#, go-format
+msgid "%d file"
+msgid_plural "%d files"
+msgstr[0] ""
+msgstr[1] ""
+
+#: hello.go:31
+msgid "Open the files"
+msgstr ""
+
+#: hello.go:41
+msgid "Old"
+msgstr ""
+
+#: hello.go:51
+msgid "Something else"
+msgstr ""
+`
+
+const testMergeExpectData = `# German translation.
+msgid ""
+msgstr ""
+"Project-Id-Version: hello\n"
+"POT-Creation-Date: 2014-01-01 10:00+0000\n"
+"Language: de\n"
+"Content-Type: text/plain; charset=UTF-8\n"
+
+# keep the translator comment
+#. TRANSLATORS: the greeting
+#: hello.go:11
+msgctxt "main.main"
+msgid "Hello, world!"
+msgstr "Hallo, Welt!"
+
+#: hello.go:21
+#, fuzzy, go-format
+msgid "%d file"
+msgid_plural "%d files"
+msgstr[0] "%d Datei"
+msgstr[1] "%d Dateien"
+
+#: hello.go:31
+#, fuzzy
+#| msgid "Open the file"
+msgid "Open the files"
+msgstr "Datei öffnen"
+
+#: hello.go:41
+msgid "Old"
+msgstr "Alt"
+
+#: hello.go:51
+msgid "Something else"
+msgstr ""
+
+#~ msgid "Removed and translated"
+#~ msgstr "Entfernt"
+`
@@ -6,8 +6,8 @@ package po
 
 import (
 	"bytes"
-	"fmt"
 	"io"
+	"reflect"
 	"strconv"
 	"strings"
 )
@@ -24,6 +24,34 @@ type Message struct {
 	MsgIdPlural  string   // msgid_plural untranslated-string-plural
 	MsgStr       string   // msgstr translated-string
 	MsgStrPlural []string // msgstr[0] translated-string-case-0
+	Obsolete     bool     // #~ msgid obsolete-untranslated-string
+
+	raw *rawMessage // the loaded entry
+}
+
+// rawMessage is the source text of the loaded entry,
+// and the message it was decoded to.
+type rawMessage struct {
+	text string
+	msg  Message
+}
+
+// clone returns a deep copy of the message, without the raw text.
+func (p *Message) clone() Message {
+	q := *p
+	q.Comment = p.Comment.clone()
+	q.MsgStrPlural = cloneStrings(p.MsgStrPlural)
+	q.raw = nil
+	return q
+}
+
+// isChanged reports whether the loaded message is changed.
+func (p *Message) isChanged() bool {
+	if p.raw == nil {
+		return true
+	}
+	q := p.clone()
+	return !reflect.DeepEqual(&q, &p.raw.msg)
 }
 
 type byMessages []Message
@@ -55,137 +83,192 @@ func (p *Message) readPoEntry(r *lineReader) (err error) {
 	if err = r.skipBlankLine(); err != nil {
 		return
 	}
-	defer func(oldPos int) {
-		newPos := r.currentPos()
-		if newPos != oldPos && err == io.EOF {
-			err = nil
-		}
-	}(r.currentPos())
-
 	if err = p.Comment.readPoComment(r); err != nil {
 		return
 	}
+
+	var (
+		startPos    = -1 // line of the first keyword
+		hasMsgCtxt  bool
+		hasMsgId    bool
+		hasMsgIdPl  bool
+		hasMsgStr   bool
+		msgStrIndex int // the next msgstr[N] index
+	)
+	if s, _, err := r.currentLine(); err == nil && isObsoleteLine(s) {
+		p.Obsolete = true
+	}
+loop:
 	for {
 		var s string
-		if s, _, err = r.currentLine(); err != nil {
-			return
-		}
-
-		if p.isInvalidLine(s) {
-			err = fmt.Errorf("gettext: line %d, %v", "invalid line")
-			return
+		var pos int
+		if s, pos, err = r.currentLine(); err != nil {
+			if err != io.EOF {
+				return
+			}
+			err = nil
+			break loop
 		}
-		if reComment.MatchString(s) || reBlankLine.MatchString(s) {
-			return
+		if reBlankLine.MatchString(s) || isObsoleteLine(s) != p.Obsolete {
+			break loop
 		}
-
-		if err = p.readMsgContext(r); err != nil {
-			return
+		t, skip := s, 0 // the line without the "#~" prefix
+		if p.Obsolete {
+			t = strings.TrimLeft(s[len("#~"):], " \t")
+			skip = len(s) - len(t)
+		} else if reComment.MatchString(s) {
+			break loop
 		}
-		if err = p.readMsgId(r); err != nil {
-			return
+		if startPos < 0 {
+			startPos = pos
 		}
-		if err = p.readMsgIdPlural(r); err != nil {
-			return
+
+		keyword, index, offset := splitKeyword(t)
+		offset += skip
+		switch keyword {
+		case "msgctxt":
+			if hasMsgStr {
+				break loop // the next message
+			}
+			if hasMsgCtxt || hasMsgId {
+				return newSyntaxError(pos, 0, "unexpected msgctxt")
+			}
+			hasMsgCtxt = true
+			p.MsgContext, err = p.readString(r, offset)
+		case "msgid":
+			if hasMsgStr {
+				break loop // the next message
+			}
+			if hasMsgId {
+				return newSyntaxError(pos, 0, "duplicate msgid")
+			}
+			hasMsgId = true
+			p.MsgId, err = p.readString(r, offset)
+		case "msgid_plural":
+			if !hasMsgId || hasMsgIdPl || hasMsgStr {
+				return newSyntaxError(pos, 0, "unexpected msgid_plural")
+			}
+			hasMsgIdPl = true
+			p.MsgIdPlural, err = p.readString(r, offset)
+		case "msgstr":
+			switch {
+			case !hasMsgId:
+				return newSyntaxError(pos, 0, "missing msgid before msgstr")
+			case index < 0 && (hasMsgStr || hasMsgIdPl):
+				return newSyntaxError(pos, 0, "unexpected msgstr")
+			case index >= 0 && !hasMsgIdPl:
+				return newSyntaxError(pos, 0, "unexpected msgstr[%d] without msgid_plural", index)
+			case index >= 0 && index != msgStrIndex:
+				return newSyntaxError(pos, skip+6, "invalid plural index %d, expect %d", index, msgStrIndex)
+			}
+			hasMsgStr = true
+			if index < 0 {
+				p.MsgStr, err = p.readString(r, offset)
+			} else {
+				var str string
+				str, err = p.readString(r, offset)
+				p.MsgStrPlural = append(p.MsgStrPlural, str)
+				msgStrIndex++
+			}
+		default:
+			if reStringLine.MatchString(t) {
+				return newSyntaxError(pos, 0, "unexpected string")
+			}
+			return newSyntaxError(pos, 0, "invalid line")
 		}
-		if err = p.readMsgStrOrPlural(r); err != nil {
+		if err != nil {
 			return
 		}
 	}
 
+	switch {
+	case startPos < 0:
+		return io.EOF // only comments at the end of file
+	case !hasMsgId:
+		return newSyntaxError(startPos, 0, "missing msgid")
+	case !hasMsgStr:
+		return newSyntaxError(startPos, 0, "missing msgstr")
+	}
 	return nil
 }
 
-func (p *Message) readMsgContext(r *lineReader) (err error) {
-	var s string
-	if s, _, err = r.currentLine(); err != nil {
-		return
-	}
-	if !reMsgContext.MatchString(s) {
-		return
+// splitKeyword splits the keyword line, like `msgstr[1] "..."`,
+// returns the keyword, the plural index (or -1), and the offset of the string.
+func splitKeyword(s string) (keyword string, index, offset int) {
+	index = -1
+	for offset < len(s) && (s[offset] == '_' || 'a' <= s[offset] && s[offset] <= 'z') {
+		offset++
 	}
-	p.MsgContext, err = p.readString(r)
-	return
-}
-
-func (p *Message) readMsgId(r *lineReader) (err error) {
-	var s string
-	if s, _, err = r.currentLine(); err != nil {
-		return
+	keyword = s[:offset]
+	if keyword == "msgstr" && offset < len(s) && s[offset] == '[' {
+		end := strings.IndexByte(s[offset:], ']')
+		if end < 0 {
+			return "", -1, 0
+		}
+		n, err := strconv.Atoi(s[offset+1 : offset+end])
+		if err != nil || n < 0 {
+			return "", -1, 0
+		}
+		index, offset = n, offset+end+1
 	}
-	if !reMsgId.MatchString(s) {
-		return
+	if offset < len(s) && s[offset] != ' ' && s[offset] != '\t' && s[offset] != '"' {
+		return "", -1, 0
 	}
-	p.MsgId, err = p.readString(r)
 	return
 }
 
-func (p *Message) readMsgIdPlural(r *lineReader) (err error) {
-	var s string
-	if s, _, err = r.currentLine(); err != nil {
-		return
-	}
-	if !reMsgIdPlural.MatchString(s) {
-		return
+// readString reads the string of the keyword line and the following
+// string lines, offset is the start of the string in the keyword line.
+func (p *Message) readString(r *lineReader, offset int) (msg string, err error) {
+	if p.Obsolete {
+		return readPoString(r, offset, "#~")
 	}
-	p.MsgIdPlural, err = p.readString(r)
-	return nil
+	return readPoString(r, offset, "")
 }
 
-func (p *Message) readMsgStrOrPlural(r *lineReader) (err error) {
-	var s string
-	if s, _, err = r.currentLine(); err != nil {
-		return
-	}
-	if !reMsgStr.MatchString(s) && !reMsgStrPlural.MatchString(s) {
-		return
-	}
-	if reMsgStrPlural.MatchString(s) {
-		left, right := strings.Index(s, `[`), strings.LastIndex(s, `]`)
-		idx, _ := strconv.Atoi(s[left+1 : right])
-		s, err = p.readString(r)
-		if n := len(p.MsgStrPlural); (idx + 1) > n {
-			p.MsgStrPlural = append(p.MsgStrPlural, make([]string, (idx+1)-n)...)
-		}
-		p.MsgStrPlural[idx] = s
-	} else {
-		p.MsgStr, err = p.readString(r)
-	}
-	return nil
+// isObsoleteLine reports whether s is a line of the obsolete message,
+// the "#~|" previous string comments are not.
+func isObsoleteLine(s string) bool {
+	return strings.HasPrefix(s, "#~") && !strings.HasPrefix(s, "#~|")
 }
 
-func (p *Message) readString(r *lineReader) (msg string, err error) {
+// readPoString reads the string at offset of the current line and the
+// following string lines, which start with the prefix.
+func readPoString(r *lineReader, offset int, prefix string) (msg string, err error) {
 	var s string
-	if s, _, err = r.readLine(); err != nil {
+	var pos int
+	if s, pos, err = r.readLine(); err != nil {
 		return
 	}
-	msg += decodePoString(s)
+	str, col, errMsg := unquotePoString(s[offset:])
+	if errMsg != "" {
+		return "", newSyntaxError(pos, offset+col, "%s", errMsg)
+	}
+	msg += str
 	for {
-		if s, _, err = r.readLine(); err != nil {
+		if s, pos, err = r.currentLine(); err != nil {
+			if err == io.EOF {
+				err = nil
+			}
 			return
 		}
-		if !reStringLine.MatchString(s) {
-			r.unreadLine()
-			break
+		if !strings.HasPrefix(s, prefix) {
+			return
 		}
-		msg += decodePoString(s)
+		if t := strings.TrimLeft(s[len(prefix):], " \t"); t == "" || t[0] != '"' {
+			return
+		}
+		r.readLine()
+		if str, col, errMsg = unquotePoString(s[len(prefix):]); errMsg != "" {
+			return "", newSyntaxError(pos, len(prefix)+col, "%s", errMsg)
+		}
+		msg += str
 	}
-	return
 }
 
 // String returns the po format entry string.
 func (p Message) String() string {
 	var buf bytes.Buffer
-	fmt.Fprintf(&buf, "%s", p.Comment.String())
-	fmt.Fprintf(&buf, "msgid %s", encodePoString(p.MsgId))
-	if p.MsgIdPlural != "" {
-		fmt.Fprintf(&buf, "msgid_plural %s", encodePoString(p.MsgIdPlural))
-	}
-	if p.MsgStr != "" {
-		fmt.Fprintf(&buf, "msgstr %s", encodePoString(p.MsgStr))
-	}
-	for i := 0; i < len(p.MsgStrPlural); i++ {
-		fmt.Fprintf(&buf, "msgstr[%d] %s", i, encodePoString(p.MsgStrPlural[i]))
-	}
+	newPoWriter(&buf, defaultWidth).writeMessage(&p)
 	return buf.String()
 }
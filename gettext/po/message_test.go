@@ -6,6 +6,7 @@ package po
 
 import (
 	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -15,7 +16,7 @@ func _TestPoEntry(t *testing.T) {
 	}
 	var entry Message
 	for i := 0; i < len(testPoEntrys); i++ {
-		if err := entry.readPoEntry(newLineReader(testPoEntryStrings[i])); err != nil {
+		if err := entry.readPoEntry(newLineReader(strings.NewReader(testPoEntryStrings[i]))); err != nil {
 			t.Fatal(err)
 		}
 		if !reflect.DeepEqual(&entry, &testPoEntrys[i]) {
@@ -0,0 +1,39 @@
+// Copyright 2013 ChaiShushan <chaishushan{AT}gmail.com>. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package po
+
+import (
+	"github.com/faxal/gettext-go/gettext/mo"
+)
+
+// FromMO returns the po file of the mo file, like GNU's msgunfmt.
+//
+// The header fields, msgctxt and plural forms are kept. The mo file has
+// no comments, so the po file has no references or flags.
+//
+// It's not a method of mo.File, because the po package imports mo.
+func FromMO(m *mo.File) *File {
+	f := &File{}
+	f.MimeHeader.parseHeader(&Message{MsgStr: m.MimeHeader.MsgStr()})
+	for _, v := range m.Messages {
+		if v.MsgContext == "" && v.MsgId == "" {
+			continue // the header entry
+		}
+		f.Messages = append(f.Messages, Message{
+			MsgContext:   v.MsgContext,
+			MsgId:        v.MsgId,
+			MsgIdPlural:  v.MsgIdPlural,
+			MsgStr:       v.MsgStr,
+			MsgStrPlural: cloneStrings(v.MsgStrPlural),
+		})
+	}
+	return f
+}
+
+// ToMO returns the mo file of the po file, it's Compile without checks.
+func (f *File) ToMO() *mo.File {
+	m, _ := f.Compile(nil) // never fails without checks
+	return m
+}
@@ -5,106 +5,135 @@
 package po
 
 import (
-	"bytes"
 	"strings"
 )
 
-func decodePoString(text string) string {
-	lines := strings.Split(text, "\n")
-	for i := 0; i < len(lines); i++ {
-		left := strings.Index(lines[i], `"`)
-		right := strings.LastIndex(lines[i], `"`)
-		if left < 0 || right < 0 || left == right {
-			lines[i] = ""
+// unquotePoString decodes the quoted string s, which may be surrounded
+// by spaces, like `  "Hello\n" `.
+//
+// If s is invalid, returns the byte offset of the error in s and
+// the description of the error.
+func unquotePoString(s string) (msg string, offset int, errMsg string) {
+	left := len(s) - len(strings.TrimLeft(s, " \t"))
+	if left >= len(s) || s[left] != '"' {
+		return "", left, "missing string"
+	}
+	right := len(strings.TrimRight(s, " \t")) - 1
+	if right <= left || s[right] != '"' || isEscapedQuote(s[left+1:right+1]) {
+		return "", left, "unterminated string"
+	}
+	msg, offset, errMsg = unescapePoString(s[left+1 : right])
+	if errMsg != "" {
+		return "", left + 1 + offset, errMsg
+	}
+	return msg, 0, ""
+}
+
+// isEscapedQuote reports whether the last quote of s is escaped.
+func isEscapedQuote(s string) bool {
+	n := 0
+	for i := len(s) - 2; i >= 0 && s[i] == '\\'; i-- {
+		n++
+	}
+	return n%2 == 1
+}
+
+// unescapePoString decodes the C escape sequences of s.
+//
+// The invalid escape sequence is kept as is, and its byte offset
+// in s and the description of the error are returned.
+func unescapePoString(s string) (msg string, offset int, errMsg string) {
+	if strings.IndexByte(s, '\\') < 0 && strings.IndexByte(s, '"') < 0 {
+		return s, 0, ""
+	}
+	offset = -1
+	data := make([]byte, 0, len(s))
+	for i := 0; i < len(s); i++ {
+		if s[i] == '"' && offset < 0 {
+			offset, errMsg = i, "unescaped quote in string"
+		}
+		if s[i] != '\\' {
+			data = append(data, s[i])
 			continue
 		}
-		line := lines[i][left+1 : right]
-		data := make([]byte, 0, len(line))
-		for i := 0; i < len(line); i++ {
-			if line[i] != '\\' {
-				data = append(data, line[i])
+		if i+1 >= len(s) {
+			if offset < 0 {
+				offset, errMsg = i, "invalid escape sequence"
+			}
+			break
+		}
+		switch c := s[i+1]; c {
+		case 'n':
+			data = append(data, '\n')
+			i++
+		case 't':
+			data = append(data, '\t')
+			i++
+		case 'r':
+			data = append(data, '\r')
+			i++
+		case 'a':
+			data = append(data, '\a')
+			i++
+		case 'b':
+			data = append(data, '\b')
+			i++
+		case 'f':
+			data = append(data, '\f')
+			i++
+		case 'v':
+			data = append(data, '\v')
+			i++
+		case '\\', '"', '\'', '?':
+			data = append(data, c)
+			i++
+		case 'x':
+			v, n := 0, 0
+			for ; i+2+n < len(s) && isHexDigit(s[i+2+n]); n++ {
+				v = v*16 + hexValue(s[i+2+n])
+			}
+			if n == 0 {
+				if offset < 0 {
+					offset, errMsg = i, "invalid escape sequence"
+				}
+				data = append(data, s[i])
 				continue
 			}
-			if i+1 >= len(line) {
-				break
+			data = append(data, byte(v))
+			i += 1 + n
+		default:
+			if c >= '0' && c <= '7' {
+				v, n := 0, 0
+				for ; n < 3 && i+1+n < len(s) && s[i+1+n] >= '0' && s[i+1+n] <= '7'; n++ {
+					v = v*8 + int(s[i+1+n]-'0')
+				}
+				data = append(data, byte(v))
+				i += n
+				continue
 			}
-			switch line[i+1] {
-			case 'n': // \\n -> \n
-				data = append(data, '\n')
-				i++
-			case 't': // \\t -> \n
-				data = append(data, '\t')
-				i++
-			case '\\': // \\\ -> ?
-				data = append(data, '\\')
-				i++
+			if offset < 0 {
+				offset, errMsg = i, "invalid escape sequence"
 			}
+			data = append(data, s[i])
 		}
-		lines[i] = string(data)
 	}
-	return strings.Join(lines, "")
+	if offset < 0 {
+		offset = 0
+	}
+	return string(data), offset, errMsg
 }
 
-func encodePoString(text string) string {
-	var buf bytes.Buffer
-	lines := strings.Split(text, "\n")
-	for i := 0; i < len(lines); i++ {
-		if lines[i] == "" {
-			if i != len(lines)-1 {
-				buf.WriteString(`"\n"` + "\n")
-			}
-			continue
-		}
-		buf.WriteRune('"')
-		for _, r := range lines[i] {
-			switch r {
-			case '\\':
-				buf.WriteString(`\\`)
-			case '"':
-				buf.WriteString(`\"`)
-			case '\n':
-				buf.WriteString(`\n`)
-			case '\t':
-				buf.WriteString(`\t`)
-			default:
-				buf.WriteRune(r)
-			}
-		}
-		buf.WriteString(`\n"` + "\n")
-	}
-	return buf.String()
+func isHexDigit(c byte) bool {
+	return '0' <= c && c <= '9' || 'a' <= c && c <= 'f' || 'A' <= c && c <= 'F'
 }
 
-func encodeCommentPoString(text string) string {
-	var buf bytes.Buffer
-	lines := strings.Split(text, "\n")
-	if len(lines) > 1 {
-		buf.WriteString(`""` + "\n")
-	}
-	for i := 0; i < len(lines); i++ {
-		if len(lines) > 0 {
-			buf.WriteString("#| ")
-		}
-		buf.WriteRune('"')
-		for _, r := range lines[i] {
-			switch r {
-			case '\\':
-				buf.WriteString(`\\`)
-			case '"':
-				buf.WriteString(`\"`)
-			case '\n':
-				buf.WriteString(`\n`)
-			case '\t':
-				buf.WriteString(`\t`)
-			default:
-				buf.WriteRune(r)
-			}
-		}
-		if i < len(lines)-1 {
-			buf.WriteString(`\n"` + "\n")
-		} else {
-			buf.WriteString(`"`)
-		}
+func hexValue(c byte) int {
+	switch {
+	case '0' <= c && c <= '9':
+		return int(c - '0')
+	case 'a' <= c && c <= 'f':
+		return int(c-'a') + 10
+	default:
+		return int(c-'A') + 10
 	}
-	return buf.String()
 }
@@ -0,0 +1,212 @@
+// Copyright 2013 ChaiShushan <chaishushan{AT}gmail.com>. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+/*
+Package tmpli18n translates the text/template and html/template templates.
+
+The first argument of the translate functions is the Translator, or the
+template data which carries it, and the msgctxt of gettext and ngettext
+is the template name after BindText or BindHTML:
+
+	{{gettext . "Hello, world!"}}
+	{{pgettext . "menu" "File"}}
+	{{printf (ngettext . "%d file" "%d files" .N) .N}}
+	{{dpngettext . "hello" "menu" "%d file" "%d files" .N}}
+
+The translated strings are escaped by html/template like other strings,
+use trustedhtml for the translated markup:
+
+	{{gettext . "<b>Hello</b>, world!" | trustedhtml}}
+
+The xgettext-go and msggoapp commands extract the strings of the template
+files of the -t flag with the same msgctxt:
+
+	xgettext-go -o hello.pot -t 'templates/*.html' ./...
+
+Examples:
+	t := template.New("").Funcs(tmpli18n.FuncMap(cat))
+	t = tmpli18n.BindHTML(template.Must(t.ParseGlob("templates/*.html")))
+
+	func hello(w http.ResponseWriter, r *http.Request) {
+		t.ExecuteTemplate(w, "hello.html", r) // translated by the request context
+	}
+*/
+package tmpli18n
+
+import (
+	"context"
+	"errors"
+	htmltemplate "html/template"
+	"strconv"
+	texttemplate "text/template"
+	"text/template/parse"
+
+	"github.com/faxal/gettext-go/gettext"
+)
+
+// FuncMap returns the translate functions of the templates:
+//
+//	gettext    data msgid
+//	pgettext   data msgctxt msgid
+//	ngettext   data msgid msgidPlural n
+//	pngettext  data msgctxt msgid msgidPlural n
+//	dpngettext data domain msgctxt msgid msgidPlural n
+//	trustedhtml s
+//
+// The data is a *gettext.Translator, a context.Context, or a value which
+// has the Translator() or Context() method, like *http.Request. If the
+// data carries no Translator, the current locale of cat is used.
+//
+// The gettext and ngettext calls must be bound to the template names
+// by BindText or BindHTML, which rewrites them to pgettext and pngettext.
+// The unbound calls fail the execution with an error.
+//
+// If cat is nil, the catalog of the package level functions is used.
+// The map can be used by both text/template and html/template.
+func FuncMap(cat *gettext.Catalog) map[string]interface{} {
+	if cat == nil {
+		cat = gettext.DefaultCatalog()
+	}
+	tr := func(data interface{}) *gettext.Translator {
+		if tr := Translator(data); tr != nil {
+			return tr
+		}
+		return cat.Locale("")
+	}
+	return map[string]interface{}{
+		"gettext": func(data interface{}, msgid string) (string, error) {
+			return "", errUnbound
+		},
+		"pgettext": func(data interface{}, msgctxt, msgid string) string {
+			return tr(data).PGettext(msgctxt, msgid)
+		},
+		"ngettext": func(data interface{}, msgid, msgidPlural string, n int) (string, error) {
+			return "", errUnbound
+		},
+		"pngettext": func(data interface{}, msgctxt, msgid, msgidPlural string, n int) string {
+			return tr(data).PNGettext(msgctxt, msgid, msgidPlural, n)
+		},
+		"dpngettext": func(data interface{}, domain, msgctxt, msgid, msgidPlural string, n int) string {
+			return tr(data).DPNGettext(domain, msgctxt, msgid, msgidPlural, n)
+		},
+		"trustedhtml": func(s string) htmltemplate.HTML {
+			return htmltemplate.HTML(s)
+		},
+	}
+}
+
+var errUnbound = errors.New("tmpli18n: the template is not bound by BindText or BindHTML")
+
+// Translator returns the Translator of the template data, or nil if
+// the data carries no Translator.
+func Translator(data interface{}) *gettext.Translator {
+	switch v := data.(type) {
+	case *gettext.Translator:
+		return v
+	case context.Context:
+		if tr, ok := gettext.FromContext(v); ok {
+			return tr
+		}
+	case interface {
+		Translator() *gettext.Translator
+	}:
+		return v.Translator()
+	case interface {
+		Context() context.Context
+	}:
+		if tr, ok := gettext.FromContext(v.Context()); ok {
+			return tr
+		}
+	}
+	return nil
+}
+
+// BindText sets the msgctxt of the gettext and ngettext calls in all the
+// templates of t to the template names, and returns t.
+//
+// It must be called after parsing, like:
+//	{{gettext . "Hello"}} -> {{pgettext . "hello.tmpl" "Hello"}}
+func BindText(t *texttemplate.Template) *texttemplate.Template {
+	for _, v := range t.Templates() {
+		if v.Tree != nil {
+			bindNode(v.Tree.Root, v.Name())
+		}
+	}
+	return t
+}
+
+// BindHTML is like BindText, but for html/template.
+//
+// It must be called after parsing and before the first execution.
+func BindHTML(t *htmltemplate.Template) *htmltemplate.Template {
+	for _, v := range t.Templates() {
+		if v.Tree != nil {
+			bindNode(v.Tree.Root, v.Name())
+		}
+	}
+	return t
+}
+
+// bindNode rewrites the gettext and ngettext calls under the node to
+// pgettext and pngettext with the msgctxt.
+func bindNode(node parse.Node, msgctxt string) {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n != nil {
+			for _, v := range n.Nodes {
+				bindNode(v, msgctxt)
+			}
+		}
+	case *parse.ActionNode:
+		bindNode(n.Pipe, msgctxt)
+	case *parse.IfNode:
+		bindBranch(&n.BranchNode, msgctxt)
+	case *parse.RangeNode:
+		bindBranch(&n.BranchNode, msgctxt)
+	case *parse.WithNode:
+		bindBranch(&n.BranchNode, msgctxt)
+	case *parse.TemplateNode:
+		bindNode(n.Pipe, msgctxt)
+	case *parse.PipeNode:
+		if n != nil {
+			for _, v := range n.Cmds {
+				bindNode(v, msgctxt)
+			}
+		}
+	case *parse.ChainNode:
+		bindNode(n.Node, msgctxt)
+	case *parse.CommandNode:
+		for _, v := range n.Args {
+			bindNode(v, msgctxt)
+		}
+		if len(n.Args) < 2 {
+			return
+		}
+		ident, ok := n.Args[0].(*parse.IdentifierNode)
+		if !ok {
+			return
+		}
+		switch ident.Ident {
+		case "gettext":
+			ident.Ident = "pgettext"
+		case "ngettext":
+			ident.Ident = "pngettext"
+		default:
+			return
+		}
+		s := &parse.StringNode{
+			NodeType: parse.NodeString,
+			Pos:      ident.Pos,
+			Quoted:   strconv.Quote(msgctxt),
+			Text:     msgctxt,
+		}
+		n.Args = append([]parse.Node{n.Args[0], n.Args[1], s}, n.Args[2:]...)
+	}
+}
+
+func bindBranch(n *parse.BranchNode, msgctxt string) {
+	bindNode(n.Pipe, msgctxt)
+	bindNode(n.List, msgctxt)
+	bindNode(n.ElseList, msgctxt)
+}
@@ -0,0 +1,141 @@
+// Copyright 2013 ChaiShushan <chaishushan{AT}gmail.com>. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package tmpli18n
+
+import (
+	"bytes"
+	"context"
+	htmltemplate "html/template"
+	"net/http/httptest"
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+	"testing/fstest"
+	texttemplate "text/template"
+
+	"github.com/faxal/gettext-go/gettext"
+)
+
+const testPoData = `msgid ""
+msgstr ""
+"Content-Type: text/plain; charset=UTF-8\n"
+"Plural-Forms: nplurals=2; plural=(n != 1);\n"
+
+msgctxt "hello.html"
+msgid "Hello"
+msgstr "<Hallo>"
+
+msgctxt "menu"
+msgid "%d file"
+msgid_plural "%d files"
+msgstr[0] "%d Datei"
+msgstr[1] "%d Dateien"
+
+msgctxt "list"
+msgid "Hello"
+msgstr "Hallo aus der Liste"
+
+msgctxt "list"
+msgid "%d item"
+msgid_plural "%d items"
+msgstr[0] "%d Eintrag"
+msgstr[1] "%d Einträge"
+`
+
+const testTemplates = `{{define "hello.html"}}{{gettext . "Hello"}}|{{gettext . "<b>Hello</b>" | trustedhtml}}|{{pgettext . "menu" "Bye"}}{{end}}` +
+	`{{define "list"}}{{range $i, $v := .Items}}{{if $i}},{{end}}{{"Hello" | gettext $}}{{end}}|{{printf (ngettext $ "%d item" "%d items" 2) 2}}|{{dpngettext $ "test" "menu" "%d file" "%d files" 1}}{{end}}`
+
+type testData struct {
+	ctx   context.Context
+	Items []int
+}
+
+func (p testData) Context() context.Context { return p.ctx }
+
+func testCatalog() *gettext.Catalog {
+	cat := gettext.NewCatalog()
+	cat.BindTextdomainFS("test", fstest.MapFS{
+		"de/LC_MESSAGES/test.po": &fstest.MapFile{Data: []byte(testPoData)},
+	})
+	cat.Textdomain("test")
+	return cat
+}
+
+func TestBindHTML(t *testing.T) {
+	cat := testCatalog()
+	tmpl := BindHTML(htmltemplate.Must(htmltemplate.New("").Funcs(FuncMap(cat)).Parse(testTemplates)))
+
+	r := httptest.NewRequest("GET", "/", nil)
+	r = r.WithContext(gettext.NewContext(r.Context(), cat.Locale("de")))
+	data := testData{ctx: r.Context(), Items: []int{1, 2}}
+	for i, v := range []struct {
+		name   string
+		data   interface{}
+		expect string
+	}{
+		{"hello.html", r, "&lt;Hallo&gt;|<b>Hello</b>|Bye"},
+		{"hello.html", cat.Locale("de"), "&lt;Hallo&gt;|<b>Hello</b>|Bye"},
+		{"hello.html", nil, "Hello|<b>Hello</b>|Bye"},
+		{"list", data, "Hallo aus der Liste,Hallo aus der Liste|2 Einträge|%d Datei"},
+	} {
+		var buf bytes.Buffer
+		if err := tmpl.ExecuteTemplate(&buf, v.name, v.data); err != nil {
+			t.Fatalf("%d: %v", i, err)
+		}
+		if got := buf.String(); got != v.expect {
+			t.Fatalf("%d: expect = %q, got = %q", i, v.expect, got)
+		}
+	}
+}
+
+func TestBindText(t *testing.T) {
+	cat := testCatalog()
+	cat.SetLocale("de")
+	tmpl := BindText(texttemplate.Must(texttemplate.New("").Funcs(FuncMap(cat)).Parse(testTemplates)))
+
+	var buf bytes.Buffer
+	if err := tmpl.ExecuteTemplate(&buf, "hello.html", nil); err != nil {
+		t.Fatal(err)
+	}
+	if got, expect := buf.String(), "<Hallo>|<b>Hello</b>|Bye"; got != expect {
+		t.Fatalf("expect = %q, got = %q", expect, got)
+	}
+}
+
+func TestFuncMap_unbound(t *testing.T) {
+	tmpl := texttemplate.Must(texttemplate.New("").Funcs(FuncMap(testCatalog())).Parse(testTemplates))
+	var buf bytes.Buffer
+	if err := tmpl.ExecuteTemplate(&buf, "hello.html", nil); err == nil || !strings.Contains(err.Error(), errUnbound.Error()) {
+		t.Fatalf("expect the unbound error, got = %v", err)
+	}
+}
+
+func TestFuncMap_miss(t *testing.T) {
+	cat := testCatalog()
+	cat.SetLocale("de")
+	c := gettext.NewMissCollector()
+	cat.SetMissHandler(c.Handle)
+	tmpl := BindText(texttemplate.Must(texttemplate.New("").Funcs(FuncMap(cat)).Parse(testTemplates)))
+
+	// the caller of the template is the reference, not the frames of
+	// tmpli18n and text/template
+	var buf bytes.Buffer
+	err := tmpl.ExecuteTemplate(&buf, "hello.html", nil)
+	_, _, line, _ := runtime.Caller(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f := c.File("test")
+	if len(f.Messages) != 2 {
+		t.Fatalf("expect = 2 messages, got = %v", f.Messages)
+	}
+	for _, v := range f.Messages {
+		file := "github.com/faxal/gettext-go/gettext/tmpli18n/tmpli18n_test.go"
+		if !reflect.DeepEqual(v.ReferenceFile, []string{file}) || !reflect.DeepEqual(v.ReferenceLine, []int{line - 1}) {
+			t.Fatalf("%q: expect = %s:%d, got = %v %v", v.MsgId, file, line-1, v.ReferenceFile, v.ReferenceLine)
+		}
+	}
+}
@@ -5,6 +5,8 @@
 package gettext
 
 import (
+	"fmt"
+
 	"github.com/faxal/gettext-go/gettext/mo"
 	"github.com/faxal/gettext-go/gettext/plural"
 	"github.com/faxal/gettext-go/gettext/po"
@@ -17,6 +19,7 @@ var nilTranslator = &translator{
 
 type translator struct {
 	MessageMap    map[string]mo.Message
+	MoReader      *mo.Reader // lazily decoded messages, used instead of MessageMap
 	PluralFormula func(n int) int
 }
 
@@ -39,10 +42,28 @@ func newMoTranslator(name string, data []byte) (*translator, error) {
 	for _, v := range f.Messages {
 		tr.MessageMap[tr.makeMapKey(v.MsgContext, v.MsgId)] = v
 	}
-	if lang := f.MimeHeader.Language; lang != "" {
-		tr.PluralFormula = plural.Formula(lang)
-	} else {
-		tr.PluralFormula = plural.Formula("??")
+	if tr.PluralFormula, err = pluralFormula(
+		name, f.MimeHeader.PluralForms, f.MimeHeader.Language,
+	); err != nil {
+		return nil, err
+	}
+	return tr, nil
+}
+
+// newMoReaderTranslator like newMoTranslator, but the messages are
+// decoded when they are looked up, and the data must not be modified.
+func newMoReaderTranslator(name string, data []byte) (*translator, error) {
+	r, err := mo.NewReaderBytes(data)
+	if err != nil {
+		return nil, err
+	}
+	var tr = &translator{
+		MoReader: r,
+	}
+	if tr.PluralFormula, err = pluralFormula(
+		name, r.Header().PluralForms, r.Header().Language,
+	); err != nil {
+		return nil, err
 	}
 	return tr, nil
 }
@@ -64,6 +85,9 @@ func newPoTranslator(name string, data []byte) (*translator, error) {
 		MessageMap: make(map[string]mo.Message),
 	}
 	for _, v := range f.Messages {
+		if v.Obsolete {
+			continue
+		}
 		tr.MessageMap[tr.makeMapKey(v.MsgContext, v.MsgId)] = mo.Message{
 			MsgContext:   v.MsgContext,
 			MsgId:        v.MsgId,
@@ -72,37 +96,74 @@ func newPoTranslator(name string, data []byte) (*translator, error) {
 			MsgStrPlural: v.MsgStrPlural,
 		}
 	}
-	if lang := f.MimeHeader.Language; lang != "" {
-		tr.PluralFormula = plural.Formula(lang)
-	} else {
-		tr.PluralFormula = plural.Formula("??")
+	if tr.PluralFormula, err = pluralFormula(
+		name, f.MimeHeader.PluralForms, f.MimeHeader.Language,
+	); err != nil {
+		return nil, err
 	}
 	return tr, nil
 }
 
+// pluralFormula returns the formula of the Plural-Forms header,
+// or the language's standard formula if the header is missing.
+func pluralFormula(name, forms, lang string) (func(n int) int, error) {
+	if forms != "" {
+		f, err := plural.ParseForms(forms)
+		if err != nil {
+			return nil, fmt.Errorf("gettext: %s: invalid Plural-Forms header: %v", name, err)
+		}
+		return f.Index, nil
+	}
+	if lang != "" {
+		return plural.Formula(lang), nil
+	}
+	return plural.Formula("??"), nil
+}
+
 func (p *translator) PGettext(msgctxt, msgid string) string {
 	return p.PNGettext(msgctxt, msgid, "", 0)
 }
 
 func (p *translator) PNGettext(msgctxt, msgid, msgidPlural string, n int) string {
-	n = p.PluralFormula(n)
-	if ss := p.findMsgStrPlural(msgctxt, msgid, msgidPlural); len(ss) != 0 {
-		if n >= len(ss) {
-			n = len(ss) - 1
-		}
-		if ss[n] != "" {
-			return ss[n]
-		}
+	if s, ok := p.lookup(msgctxt, msgid, msgidPlural, n); ok {
+		return s
 	}
-	if msgidPlural != "" && n > 0 {
+	if msgidPlural != "" && p.PluralFormula(n) > 0 {
 		return msgidPlural
 	}
 	return msgid
 }
 
+// lookup returns the translation, reports whether it was found.
+func (p *translator) lookup(msgctxt, msgid, msgidPlural string, n int) (string, bool) {
+	ss := p.findMsgStrPlural(msgctxt, msgid, msgidPlural)
+	if len(ss) == 0 {
+		return "", false
+	}
+	n = p.PluralFormula(n)
+	if n >= len(ss) {
+		n = len(ss) - 1
+	}
+	if n < 0 {
+		n = 0
+	}
+	if ss[n] == "" {
+		return "", false
+	}
+	return ss[n], true
+}
+
 func (p *translator) findMsgStrPlural(msgctxt, msgid, msgidPlural string) []string {
-	key := p.makeMapKey(msgctxt, msgid)
-	if v, ok := p.MessageMap[key]; ok {
+	var (
+		v  mo.Message
+		ok bool
+	)
+	if p.MoReader != nil {
+		v, ok = p.MoReader.Lookup(msgctxt, msgid)
+	} else {
+		v, ok = p.MessageMap[p.makeMapKey(msgctxt, msgid)]
+	}
+	if ok {
 		if len(v.MsgIdPlural) != 0 {
 			if len(v.MsgStrPlural) != 0 {
 				return v.MsgStrPlural
@@ -5,6 +5,8 @@
 package gettext
 
 import (
+	"io/ioutil"
+	"strings"
 	"testing"
 
 	"github.com/faxal/gettext-go/gettext/mo"
@@ -36,6 +38,53 @@ func TestTranslator_Mo(t *testing.T) {
 	}
 }
 
+func TestTranslator_MoReader(t *testing.T) {
+	tr, err := newMoReaderTranslator("test", poToMoData(t, []byte(testTrPoData)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, v := range testTrData {
+		if out := tr.PGettext(v.msgctxt, v.msgid); out != v.msgstr {
+			t.Fatalf("%s/%s: expect = %s, got = %s", v.msgctxt, v.msgid, v.msgstr, out)
+		}
+	}
+	tr, err = newMoReaderTranslator("test", poToMoData(t, []byte(testTrPluralPoData)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, v := range testTrPluralData {
+		if out := tr.PNGettext("", "%d file", "%d files", v.n); out != v.msgstr {
+			t.Fatalf("%d: n = %d, expect = %s, got = %s", i, v.n, v.msgstr, out)
+		}
+	}
+}
+
+func TestTranslator_PluralForms(t *testing.T) {
+	tr, err := newPoTranslator("test", []byte(testTrPluralPoData))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, v := range testTrPluralData {
+		if out := tr.PNGettext("", "%d file", "%d files", v.n); out != v.msgstr {
+			t.Fatalf("%d: n = %d, expect = %s, got = %s", i, v.n, v.msgstr, out)
+		}
+	}
+	tr, err = newMoTranslator("test", poToMoData(t, []byte(testTrPluralPoData)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, v := range testTrPluralData {
+		if out := tr.PNGettext("", "%d file", "%d files", v.n); out != v.msgstr {
+			t.Fatalf("%d: n = %d, expect = %s, got = %s", i, v.n, v.msgstr, out)
+		}
+	}
+
+	bad := strings.Replace(testTrPluralPoData, "plural=", "plural=(", 1)
+	if _, err := newPoTranslator("test", []byte(bad)); err == nil {
+		t.Fatalf("expect error for invalid Plural-Forms")
+	}
+}
+
 func poToMoData(t *testing.T, data []byte) []byte {
 	poFile, err := po.LoadData(data)
 	if err != nil {
@@ -98,3 +147,52 @@ msgctxt "github.com/faxal/gettext-go/examples/hi.SayHi"
 msgid "pkg hi: Hello, world!"
 msgstr "来自\"Hi\"包的问候: 你好, 世界!"
 `
+
+var testTrPluralData = []struct {
+	n      int
+	msgstr string
+}{
+	{1, "%d file(one)"},
+	{2, "%d file(few)"},
+	{4, "%d file(few)"},
+	{5, "%d file(many)"},
+	{11, "%d file(many)"},
+	{21, "%d file(one)"},
+	{22, "%d file(few)"},
+}
+
+// Language "xx" is not in the plural table, the header's rule is used.
+var testTrPluralPoData = `
+msgid ""
+msgstr ""
+"Language: xx\n"
+"Plural-Forms: nplurals=3; plural=(n%10==1 && n%100!=11 ? 0 : n%10>=2 && n%10<=4 && (n%100<10 || n%100>=20) ? 1 : 2);\n"
+
+msgid "%d file"
+msgid_plural "%d files"
+msgstr[0] "%d file(one)"
+msgstr[1] "%d file(few)"
+msgstr[2] "%d file(many)"
+`
+
+func BenchmarkNewMoTranslator(b *testing.B) {
+	benchmarkNewTranslator(b, newMoTranslator)
+}
+
+func BenchmarkNewMoReaderTranslator(b *testing.B) {
+	benchmarkNewTranslator(b, newMoReaderTranslator)
+}
+
+func benchmarkNewTranslator(b *testing.B, newTranslator func(name string, data []byte) (*translator, error)) {
+	data, err := ioutil.ReadFile(testDataDir + "mm-viet.comp.mo")
+	if err != nil {
+		b.Fatal(err)
+	}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := newTranslator("mm-viet.comp.mo", data); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,244 @@
+// Copyright 2013 ChaiShushan <chaishushan{AT}gmail.com>. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gettext
+
+import (
+	"bytes"
+	"io/fs"
+	"os"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ReloadEvent reports a reload of the watched translations.
+type ReloadEvent struct {
+	Domain string // the domain of the reloaded files
+	Locale string // the locale of the reloaded files, empty if the error is of the domain
+	Err    error  // the error of the reload, the old translations are kept
+}
+
+// fileStamp is the modification time and the size of a file,
+// the zero value means the file doesn't exist.
+type fileStamp struct {
+	modTime int64
+	size    int64
+}
+
+// localeStamp is the stamps of the mo and po files of a locale.
+type localeStamp struct {
+	mo, po fileStamp
+}
+
+type watchDomain struct {
+	fs      *fileSystem
+	zip     fileStamp
+	locales map[string]localeStamp
+}
+
+// watcher polls the files of the bound domains, and reloads the changed
+// translators.
+type watcher struct {
+	manager *domainManager
+	fn      func(ReloadEvent)
+	domains map[string]*watchDomain
+}
+
+func newWatcher(manager *domainManager, fn func(ReloadEvent)) *watcher {
+	if fn == nil {
+		fn = func(ReloadEvent) {}
+	}
+	w := &watcher{
+		manager: manager,
+		fn:      fn,
+		domains: make(map[string]*watchDomain),
+	}
+	w.poll() // the stamps of the bound files
+	return w
+}
+
+// start polls the files every interval, until stop is called.
+func (w *watcher) start(interval time.Duration) (stop func()) {
+	var once sync.Once
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				w.poll()
+			case <-done:
+				return
+			}
+		}
+	}()
+	return func() {
+		once.Do(func() { close(done) })
+	}
+}
+
+// poll reloads the translators whose files are changed since last poll.
+//
+// The new translators are loaded without the lock, and then swapped in
+// at the same time, so the lookups never see a half-loaded domain.
+func (w *watcher) poll() {
+	p := w.manager
+	p.mutex.RLock()
+	bound := make(map[string]*fileSystem, len(p.domainMap))
+	for k, v := range p.domainMap {
+		bound[k] = v
+	}
+	p.mutex.RUnlock()
+
+	for domain := range w.domains {
+		if _, ok := bound[domain]; !ok {
+			delete(w.domains, domain)
+		}
+	}
+	var domains []string
+	for domain := range bound {
+		domains = append(domains, domain)
+	}
+	sort.Strings(domains)
+	for _, domain := range domains {
+		w.pollDomain(domain, bound[domain])
+	}
+}
+
+func (w *watcher) pollDomain(domain string, fs *fileSystem) {
+	old, ok := w.domains[domain]
+	if !ok || old.fs != fs {
+		// the domain is just bound
+		w.domains[domain] = &watchDomain{
+			fs:      fs,
+			zip:     zipStamp(fs),
+			locales: localeStamps(fs, domain),
+		}
+		return
+	}
+	if fs.Fs == nil {
+		return
+	}
+
+	// the file system with the current files
+	newFs := &fileSystem{FsName: fs.FsName, Fs: fs.Fs, ZipFile: fs.ZipFile}
+	zip := zipStamp(fs)
+	var err error
+	if zip != old.zip {
+		err = newFs.init(nil)
+	} else {
+		err = newFs.lsLocales()
+	}
+	if err != nil {
+		w.fn(ReloadEvent{Domain: domain, Err: err})
+		old.zip = zip
+		return
+	}
+
+	var (
+		events  []ReloadEvent
+		changed = make(map[string]*translator)
+		stamps  = localeStamps(newFs, domain)
+		current = fs
+	)
+	for locale, stamp := range stamps {
+		oldStamp, ok := old.locales[locale]
+		if ok && oldStamp == stamp && (zip == old.zip || sameMessages(fs, newFs, domain, locale)) {
+			continue
+		}
+		tr, err := loadTranslatorFile(newFs, domain, locale)
+		events = append(events, ReloadEvent{Domain: domain, Locale: locale, Err: err})
+		if err == nil {
+			changed[locale] = tr
+		}
+	}
+	for locale := range old.locales {
+		if _, ok := stamps[locale]; !ok {
+			events = append(events, ReloadEvent{Domain: domain, Locale: locale})
+		}
+	}
+	if zip != old.zip || len(events) != 0 {
+		p := w.manager
+		p.mutex.Lock()
+		if p.domainMap[domain] != fs {
+			// the domain is bound again
+			p.mutex.Unlock()
+			return
+		}
+		for locale, tr := range changed {
+			p.trTextMap[p.makeTrMapKey(domain, locale)] = tr
+		}
+		for locale := range old.locales {
+			if _, ok := stamps[locale]; !ok {
+				delete(p.trTextMap, p.makeTrMapKey(domain, locale))
+			}
+		}
+		p.domainMap[domain] = newFs
+		p.resetLocaleChains()
+		p.mutex.Unlock()
+		current = newFs
+	}
+
+	w.domains[domain] = &watchDomain{
+		fs:      current,
+		zip:     zip,
+		locales: stamps,
+	}
+	sort.Slice(events, func(i, j int) bool {
+		return events[i].Locale < events[j].Locale
+	})
+	for _, ev := range events {
+		w.fn(ev)
+	}
+}
+
+// zipStamp returns the stamp of the local zip file.
+func zipStamp(fs *fileSystem) fileStamp {
+	if !fs.ZipFile {
+		return fileStamp{}
+	}
+	fi, err := os.Stat(fs.FsName)
+	if err != nil {
+		return fileStamp{}
+	}
+	return fileStamp{fi.ModTime().UnixNano(), fi.Size()}
+}
+
+// localeStamps returns the stamps of the messages files of the locales.
+func localeStamps(fs *fileSystem, domain string) map[string]localeStamp {
+	stamps := make(map[string]localeStamp)
+	for locale := range fs.LocaleMap {
+		stamps[locale] = localeStamp{
+			mo: messagesStamp(fs, domain, locale, ".mo"),
+			po: messagesStamp(fs, domain, locale, ".po"),
+		}
+	}
+	return stamps
+}
+
+func messagesStamp(p *fileSystem, domain, locale, ext string) fileStamp {
+	if p.Fs == nil {
+		return fileStamp{}
+	}
+	fi, err := fs.Stat(p.Fs, p.makeMessagesFileName(domain, locale, ext))
+	if err != nil {
+		return fileStamp{}
+	}
+	return fileStamp{fi.ModTime().UnixNano(), fi.Size()}
+}
+
+// sameMessages reports whether the messages files of the locale are the
+// same in the two file systems.
+func sameMessages(a, b *fileSystem, domain, locale string) bool {
+	for _, ext := range []string{".mo", ".po"} {
+		x, errX := a.LoadMessagesFile(domain, locale, ext)
+		y, errY := b.LoadMessagesFile(domain, locale, ext)
+		if (errX == nil) != (errY == nil) || !bytes.Equal(x, y) {
+			return false
+		}
+	}
+	return true
+}
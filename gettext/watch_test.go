@@ -0,0 +1,208 @@
+// Copyright 2013 ChaiShushan <chaishushan{AT}gmail.com>. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package gettext
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+func testWatchPo(msgstr string) []byte {
+	return []byte(fmt.Sprintf(`msgid ""
+msgstr ""
+"Content-Type: text/plain; charset=UTF-8\n"
+
+msgctxt "ctx"
+msgid "Hello"
+msgstr %q
+`, msgstr))
+}
+
+// testWriteFile replaces the file with a new modification time, the
+// watcher never sees a half-written file.
+func testWriteFile(t *testing.T, name string, data []byte, modTime time.Time) {
+	if err := os.MkdirAll(filepath.Dir(name), 0777); err != nil {
+		t.Fatal(err)
+	}
+	tmp := filepath.Join(t.TempDir(), filepath.Base(name))
+	if err := ioutil.WriteFile(tmp, data, 0666); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(tmp, modTime, modTime); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Rename(tmp, name); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestWatch_dir(t *testing.T) {
+	dir := t.TempDir()
+	modTime := time.Now().Add(-time.Hour)
+	testWriteFile(t, filepath.Join(dir, "de/LC_MESSAGES/test.po"), testWatchPo("Hallo"), modTime)
+	testWriteFile(t, filepath.Join(dir, "ja/LC_MESSAGES/test.po"), testWatchPo("こんにちは"), modTime)
+
+	cat := NewCatalog()
+	cat.BindTextdomain("test", dir, nil)
+	cat.Textdomain("test")
+
+	var events []string
+	w := newWatcher(cat.manager, func(ev ReloadEvent) {
+		events = append(events, testEventString(ev))
+	})
+	for i, v := range []struct {
+		update func()
+		events []string
+		de, fr string
+	}{
+		{
+			func() {},
+			nil, "Hallo", "Hello",
+		},
+		{
+			func() {
+				testWriteFile(t, filepath.Join(dir, "de/LC_MESSAGES/test.po"), testWatchPo("Hallo!"), modTime.Add(time.Minute))
+				testWriteFile(t, filepath.Join(dir, "fr/LC_MESSAGES/test.po"), testWatchPo("Bonjour"), modTime)
+			},
+			[]string{"test/de", "test/fr"}, "Hallo!", "Bonjour",
+		},
+		{
+			func() {
+				testWriteFile(t, filepath.Join(dir, "de/LC_MESSAGES/test.po"), []byte(`msgid "Hello`), modTime.Add(2*time.Minute))
+				os.RemoveAll(filepath.Join(dir, "fr"))
+			},
+			[]string{"test/de error", "test/fr"}, "Hallo!", "Hello",
+		},
+		{
+			func() {},
+			nil, "Hallo!", "Hello",
+		},
+	} {
+		events = nil
+		v.update()
+		w.poll()
+		if !reflect.DeepEqual(events, v.events) {
+			t.Fatalf("%d: expect = %q, got = %q", i, v.events, events)
+		}
+		if s := cat.Locale("de").PGettext("ctx", "Hello"); s != v.de {
+			t.Fatalf("%d: expect = %q, got = %q", i, v.de, s)
+		}
+		if s := cat.Locale("fr").PGettext("ctx", "Hello"); s != v.fr {
+			t.Fatalf("%d: expect = %q, got = %q", i, v.fr, s)
+		}
+		if s := cat.Locale("ja").PGettext("ctx", "Hello"); s != "こんにちは" {
+			t.Fatalf("%d: expect = %q, got = %q", i, "こんにちは", s)
+		}
+	}
+}
+
+func TestWatch_zip(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "local.zip")
+	modTime := time.Now().Add(-time.Hour)
+	testWriteFile(t, name, testWatchZip(t, "Hallo", "こんにちは"), modTime)
+
+	cat := NewCatalog()
+	cat.BindTextdomain("test", name, nil)
+
+	var events []string
+	w := newWatcher(cat.manager, func(ev ReloadEvent) {
+		events = append(events, testEventString(ev))
+	})
+	testWriteFile(t, name, testWatchZip(t, "Hallo!", "こんにちは"), modTime.Add(time.Minute))
+	w.poll()
+	if expect := []string{"test/de"}; !reflect.DeepEqual(events, expect) {
+		t.Fatalf("expect = %q, got = %q", expect, events)
+	}
+	if s := cat.Locale("de").DPGettext("test", "ctx", "Hello"); s != "Hallo!" {
+		t.Fatalf("expect = %q, got = %q", "Hallo!", s)
+	}
+	if s := cat.Locale("ja").DPGettext("test", "ctx", "Hello"); s != "こんにちは" {
+		t.Fatalf("expect = %q, got = %q", "こんにちは", s)
+	}
+}
+
+func TestWatch_concurrent(t *testing.T) {
+	dir := t.TempDir()
+	modTime := time.Now().Add(-time.Hour)
+	testWriteFile(t, filepath.Join(dir, "de/LC_MESSAGES/test.po"), testWatchPo("Hallo"), modTime)
+
+	cat := NewCatalog()
+	cat.BindTextdomain("test", dir, nil)
+	cat.Textdomain("test")
+	reloaded := make(chan ReloadEvent, 1)
+	stop := cat.Watch(time.Millisecond, func(ev ReloadEvent) {
+		reloaded <- ev
+	})
+	defer stop()
+
+	var wg sync.WaitGroup
+	done := make(chan struct{})
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			tr := cat.Locale("de")
+			for {
+				select {
+				case <-done:
+					return
+				default:
+				}
+				if s := tr.PGettext("ctx", "Hello"); s != "Hallo" && s != "Hallo!" {
+					t.Errorf("bad translation: %q", s)
+					return
+				}
+			}
+		}()
+	}
+	testWriteFile(t, filepath.Join(dir, "de/LC_MESSAGES/test.po"), testWatchPo("Hallo!"), modTime.Add(time.Minute))
+	select {
+	case ev := <-reloaded:
+		if ev.Domain != "test" || ev.Locale != "de" || ev.Err != nil {
+			t.Errorf("bad event: %+v", ev)
+		}
+	case <-time.After(10 * time.Second):
+		t.Errorf("timeout")
+	}
+	close(done)
+	wg.Wait()
+	if s := cat.Locale("de").PGettext("ctx", "Hello"); s != "Hallo!" {
+		t.Fatalf("expect = %q, got = %q", "Hallo!", s)
+	}
+}
+
+func testEventString(ev ReloadEvent) string {
+	if ev.Err != nil {
+		return ev.Domain + "/" + ev.Locale + " error"
+	}
+	return ev.Domain + "/" + ev.Locale
+}
+
+func testWatchZip(t *testing.T, de, ja string) []byte {
+	var buf bytes.Buffer
+	w := zip.NewWriter(&buf)
+	for name, data := range map[string][]byte{
+		"local/de/LC_MESSAGES/test.po": testWatchPo(de),
+		"local/ja/LC_MESSAGES/test.po": testWatchPo(ja),
+	} {
+		f, err := w.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		f.Write(data)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
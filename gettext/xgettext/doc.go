@@ -0,0 +1,51 @@
+// Copyright 2013 ChaiShushan <chaishushan{AT}gmail.com>. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+/*
+Package xgettext extracts the translatable strings of Go packages and
+templates.
+
+The gettext.Gettext and the other functions without msgctxt argument use
+the caller's function name as the msgctxt, the extractor computes the
+same msgctxt from the source code:
+
+	func main() {...}            // main.main
+	func init() {...}            // main.init
+	var x = gettext.Gettext(...) // main.init
+	func (p *T) Get() {...}      // github.com/faxal/hello.(*T).Get
+	func() {...}()               // github.com/faxal/hello.func
+
+The comments which start with "TRANSLATORS:" just above the call are
+extracted for translators, and the msgid with the verbs of the fmt package
+has the "go-format" flag:
+
+	// TRANSLATORS: %d is the number of files.
+	fmt.Printf(gettext.NGettext("%d file", "%d files", n), n)
+
+The wrappers of the translate functions can be added as keywords:
+
+	k, _ := xgettext.ParseKeyword("T:1c,2") // func T(msgctxt, msgid string) string
+	x.Keywords = append(append([]xgettext.Keyword(nil), xgettext.DefaultKeywords...), k)
+
+The templates of text/template and html/template are parsed by
+text/template/parse, the calls of the tmpli18n functions in the actions
+and pipelines are extracted, and the msgctxt of gettext and ngettext is
+the template name:
+
+	{{gettext . "Hello"}}            // msgctxt is "hello.html", or the {{define}} name
+	{{"Hello" | pgettext . "menu"}}  // msgctxt is "menu"
+
+Examples:
+	x := xgettext.NewExtractor()
+	if err := x.ExtractPackage("github.com/faxal/hello"); err != nil {
+		log.Fatal(err)
+	}
+	if err := x.ExtractTemplateFiles([]string{"templates/hello.html"}); err != nil {
+		log.Fatal(err)
+	}
+	if err := x.File().Save("hello.pot"); err != nil {
+		log.Fatal(err)
+	}
+*/
+package xgettext
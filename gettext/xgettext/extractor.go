@@ -0,0 +1,301 @@
+// Copyright 2013 ChaiShushan <chaishushan{AT}gmail.com>. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xgettext
+
+import (
+	"fmt"
+	"go/ast"
+	"go/constant"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/faxal/gettext-go/gettext/po"
+)
+
+// Warning reports a translate call which can't be extracted,
+// like a call with a msgid which isn't a constant string.
+type Warning struct {
+	Pos token.Position // position of the argument
+	Msg string         // description of warning
+}
+
+func (w *Warning) String() string {
+	return fmt.Sprintf("%v: %s", w.Pos, w.Msg)
+}
+
+// Extractor extracts the translatable strings of Go packages and templates.
+type Extractor struct {
+	Keywords         []Keyword // the translate functions, nil means DefaultKeywords
+	TemplateKeywords []Keyword // the translate functions of templates, nil means DefaultTemplateKeywords
+	CommentTag       string    // the tag of the comments for translators, empty means none
+
+	fset     *token.FileSet
+	importer types.Importer
+	messages []po.Message
+	index    map[string]int // index of messages, by msgctxt and msgid
+	warnings []*Warning
+}
+
+// NewExtractor returns a new extractor, the comments which start with
+// "TRANSLATORS:" are extracted.
+func NewExtractor() *Extractor {
+	fset := token.NewFileSet()
+	return &Extractor{
+		CommentTag: "TRANSLATORS:",
+		fset:       fset,
+		importer:   importer.ForCompiler(fset, "source", nil),
+		index:      make(map[string]int),
+	}
+}
+
+// Warnings returns the warnings of the extracted packages.
+func (p *Extractor) Warnings() []*Warning {
+	return p.warnings
+}
+
+// ExtractPackage extracts the strings of a Go package, path is an import
+// path, or a directory like "./cmd/hello", see ImportPackage.
+func (p *Extractor) ExtractPackage(path string) error {
+	bp, err := ImportPackage(path)
+	if err != nil {
+		return err
+	}
+	var names []string
+	for _, name := range append(bp.GoFiles, bp.CgoFiles...) {
+		names = append(names, filepath.Join(bp.Dir, name))
+	}
+	return p.ExtractFiles(bp.ImportPath, names)
+}
+
+// ExtractFiles extracts the strings of the Go files of a package.
+//
+// The type errors are ignored, so the strings of a package which
+// can't be built are extracted too.
+func (p *Extractor) ExtractFiles(importPath string, filenames []string) error {
+	var files []*ast.File
+	for _, name := range filenames {
+		f, err := parser.ParseFile(p.fset, name, nil, parser.ParseComments)
+		if err != nil {
+			return fmt.Errorf("gettext: %v", err)
+		}
+		files = append(files, f)
+	}
+	info := &types.Info{
+		Types: make(map[ast.Expr]types.TypeAndValue),
+		Uses:  make(map[*ast.Ident]types.Object),
+	}
+	conf := types.Config{
+		Importer: p.importer,
+		Error:    func(err error) {},
+	}
+	conf.Check(importPath, p.fset, files, info)
+
+	for _, f := range files {
+		pkgName := pkgSymbolPrefix(importPath)
+		if f.Name.Name == "main" {
+			pkgName = "main"
+		}
+		p.extractFile(importPath, pkgName, f, info)
+	}
+	return nil
+}
+
+// File returns the extracted messages as a po template file.
+func (p *Extractor) File() *po.File {
+	return &po.File{
+		MimeHeader: po.NewTemplateHeader(),
+		Messages:   append([]po.Message(nil), p.messages...),
+	}
+}
+
+func (p *Extractor) extractFile(importPath, pkgName string, f *ast.File, info *types.Info) {
+	// the comments, by the end line
+	comments := make(map[int]*ast.CommentGroup)
+	for _, c := range f.Comments {
+		comments[p.fset.Position(c.End()).Line] = c
+	}
+
+	var stack []ast.Node
+	ast.Inspect(f, func(n ast.Node) bool {
+		if n == nil {
+			stack = stack[:len(stack)-1]
+			return true
+		}
+		stack = append(stack, n)
+		if call, ok := n.(*ast.CallExpr); ok {
+			if k := p.keyword(call, info); k != nil {
+				p.extractCall(importPath, pkgName, call, k, stack, info, comments)
+			}
+		}
+		return true
+	})
+}
+
+func (p *Extractor) extractCall(importPath, pkgName string, call *ast.CallExpr, k *Keyword, stack []ast.Node, info *types.Info, comments map[int]*ast.CommentGroup) {
+	var msg po.Message
+	var ok bool
+	if msg.MsgId, ok = p.stringArg(call, k.MsgId, "msgid", info); !ok {
+		return
+	}
+	if k.MsgIdPlural > 0 {
+		if msg.MsgIdPlural, ok = p.stringArg(call, k.MsgIdPlural, "msgid_plural", info); !ok {
+			return
+		}
+	}
+	if k.MsgContext > 0 {
+		if msg.MsgContext, ok = p.stringArg(call, k.MsgContext, "msgctxt", info); !ok {
+			return
+		}
+	} else if k.CallerContext {
+		msg.MsgContext = callerName(pkgName, stack)
+	}
+
+	pos := p.fset.Position(call.Lparen)
+	file := path.Join(importPath, filepath.Base(pos.Filename))
+	p.addMessage(msg, file, pos.Line, p.translatorComment(call, stack, comments))
+}
+
+// addMessage adds the message with the reference, or merges it into the
+// extracted message of the same msgctxt and msgid.
+func (p *Extractor) addMessage(msg po.Message, file string, line int, comment string) {
+	key := msg.MsgContext + "\x04" + msg.MsgId
+	if i, ok := p.index[key]; ok {
+		m := &p.messages[i]
+		m.ReferenceFile = append(m.ReferenceFile, file)
+		m.ReferenceLine = append(m.ReferenceLine, line)
+		if m.MsgIdPlural == "" {
+			m.MsgIdPlural = msg.MsgIdPlural
+			m.Flags = formatFlags(m)
+		}
+		if comment != "" && !containsLine(m.ExtractedComment, comment) {
+			if m.ExtractedComment != "" {
+				m.ExtractedComment += "\n"
+			}
+			m.ExtractedComment += comment
+		}
+		return
+	}
+	msg.ExtractedComment = comment
+	msg.ReferenceFile = []string{file}
+	msg.ReferenceLine = []int{line}
+	msg.Flags = formatFlags(&msg)
+	p.index[key] = len(p.messages)
+	p.messages = append(p.messages, msg)
+}
+
+// translatorComment returns the comment for translators, which is just
+// above the call, or above the statement of the call.
+func (p *Extractor) translatorComment(call *ast.CallExpr, stack []ast.Node, comments map[int]*ast.CommentGroup) string {
+	if p.CommentTag == "" {
+		return ""
+	}
+	lines := []int{p.fset.Position(call.Pos()).Line}
+	for i := len(stack) - 1; i >= 0; i-- {
+		switch n := stack[i].(type) {
+		case ast.Stmt, ast.Spec, *ast.GenDecl:
+			lines = append(lines, p.fset.Position(n.Pos()).Line)
+		default:
+			continue
+		}
+		break
+	}
+	for _, line := range lines {
+		c, ok := comments[line-1]
+		if !ok || c.End() > call.Pos() {
+			continue
+		}
+		if text := strings.TrimSpace(c.Text()); strings.HasPrefix(text, p.CommentTag) {
+			return text
+		}
+	}
+	return ""
+}
+
+// containsLine reports whether the lines of s contain all the lines of t.
+func containsLine(s, t string) bool {
+	return strings.Contains("\n"+s+"\n", "\n"+t+"\n")
+}
+
+// keyword returns the keyword of the called function, or nil.
+func (p *Extractor) keyword(call *ast.CallExpr, info *types.Info) *Keyword {
+	name := calleeName(call, info)
+	if name == "" {
+		return nil
+	}
+	keywords := p.Keywords
+	if keywords == nil {
+		keywords = DefaultKeywords
+	}
+	for i := 0; i < len(keywords); i++ {
+		if keywords[i].match(name) {
+			return &keywords[i]
+		}
+	}
+	return nil
+}
+
+// calleeName returns the full name of the called function or method.
+func calleeName(call *ast.CallExpr, info *types.Info) string {
+	fun := call.Fun
+	for {
+		if x, ok := fun.(*ast.ParenExpr); ok {
+			fun = x.X
+			continue
+		}
+		break
+	}
+	var id *ast.Ident
+	switch fun := fun.(type) {
+	case *ast.Ident:
+		id = fun
+	case *ast.SelectorExpr:
+		id = fun.Sel
+		// the imported package may fail to type check
+		if x, ok := fun.X.(*ast.Ident); ok && info.Uses[id] == nil {
+			if pkg, ok := info.Uses[x].(*types.PkgName); ok {
+				return pkg.Imported().Path() + "." + id.Name
+			}
+		}
+	default:
+		return ""
+	}
+	if fn, ok := info.Uses[id].(*types.Func); ok {
+		return fn.FullName()
+	}
+	return ""
+}
+
+// stringArg returns the constant string of the call's argument at pos,
+// name is the name of the argument in the warnings.
+func (p *Extractor) stringArg(call *ast.CallExpr, pos int, name string, info *types.Info) (string, bool) {
+	if pos > len(call.Args) {
+		p.warnf(call.Rparen, "missing %s argument %d", name, pos)
+		return "", false
+	}
+	arg := call.Args[pos-1]
+	if tv, ok := info.Types[arg]; ok && tv.Value != nil && tv.Value.Kind() == constant.String {
+		return constant.StringVal(tv.Value), true
+	}
+	if lit, ok := arg.(*ast.BasicLit); ok && lit.Kind == token.STRING {
+		if s, err := strconv.Unquote(lit.Value); err == nil {
+			return s, true
+		}
+	}
+	p.warnf(arg.Pos(), "%s argument is not a constant string", name)
+	return "", false
+}
+
+func (p *Extractor) warnf(pos token.Pos, format string, args ...interface{}) {
+	p.warnings = append(p.warnings, &Warning{
+		Pos: p.fset.Position(pos),
+		Msg: fmt.Sprintf(format, args...),
+	})
+}
@@ -0,0 +1,392 @@
+// Copyright 2013 ChaiShushan <chaishushan{AT}gmail.com>. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xgettext
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/faxal/gettext-go/gettext/po"
+)
+
+func TestExtractor(t *testing.T) {
+	x := NewExtractor()
+	k, err := ParseKeyword("T:1c,2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	x.Keywords = append(append([]Keyword(nil), DefaultKeywords...), k)
+	if err := x.ExtractFiles("example.com/hello", []string{"testdata/hello/hello.go"}); err != nil {
+		t.Fatal(err)
+	}
+	f := x.File()
+	if len(f.Messages) != len(testHelloMessages) {
+		t.Fatalf("expect = %d messages, got = %v", len(testHelloMessages), f.Messages)
+	}
+	for i, v := range f.Messages {
+		if !reflect.DeepEqual(&v, &testHelloMessages[i]) {
+			t.Fatalf("%d: expect = %#v, got = %#v", i, testHelloMessages[i], v)
+		}
+	}
+
+	var warnings []string
+	for _, w := range x.Warnings() {
+		warnings = append(warnings, w.String())
+	}
+	expect := []string{
+		"testdata/hello/hello.go:43:13: msgid argument is not a constant string",
+		"testdata/hello/hello.go:54:30: msgid argument is not a constant string",
+	}
+	if !reflect.DeepEqual(warnings, expect) {
+		t.Fatalf("expect = %q, got = %q", expect, warnings)
+	}
+}
+
+func TestParseKeyword(t *testing.T) {
+	for _, v := range []struct {
+		spec string
+		k    Keyword
+		ok   bool
+	}{
+		{"T", Keyword{Name: "T", MsgId: 1}, true},
+		{"T:2", Keyword{Name: "T", MsgId: 2}, true},
+		{"T:1c,2", Keyword{Name: "T", MsgContext: 1, MsgId: 2}, true},
+		{"i18n.N:2,3", Keyword{Name: "i18n.N", MsgId: 2, MsgIdPlural: 3}, true},
+		{"i18n.PN:3,1c,4", Keyword{Name: "i18n.PN", MsgContext: 1, MsgId: 3, MsgIdPlural: 4}, true},
+		{"", Keyword{}, false},
+		{"T:", Keyword{Name: "T", MsgId: 1}, true},
+		{"T:1c", Keyword{}, false},
+		{"T:0", Keyword{}, false},
+		{"T:1,2,3", Keyword{}, false},
+		{"T:x", Keyword{}, false},
+	} {
+		k, err := ParseKeyword(v.spec)
+		if (err == nil) != v.ok || k != v.k {
+			t.Fatalf("%q: expect = %v/%v, got = %v/%v", v.spec, v.k, v.ok, k, err)
+		}
+	}
+}
+
+func TestKeyword_match(t *testing.T) {
+	for _, v := range []struct {
+		name     string
+		fullName string
+		ok       bool
+	}{
+		{"T", "example.com/i18n.T", true},
+		{"i18n.T", "example.com/i18n.T", true},
+		{"i18n.T", "i18n.T", true},
+		{"T", "(*example.com/i18n.Locale).T", true},
+		{"T", "example.com/i18n.PT", false},
+		{"i18n.T", "example.com/xi18n.T", false},
+		{"(*example.com/i18n.Locale).T", "(*example.com/i18n.Locale).T", true},
+	} {
+		k := Keyword{Name: v.name}
+		if ok := k.match(v.fullName); ok != v.ok {
+			t.Fatalf("%s/%s: expect = %v, got = %v", v.name, v.fullName, v.ok, ok)
+		}
+	}
+}
+
+// The examples use the gettext.Gettext and the gettext.PGettext with the
+// msgctxt of callerName, they are extracted to the same message.
+func TestExtractor_ctx(t *testing.T) {
+	x := NewExtractor()
+	if err := x.ExtractFiles("example.com/ctx", []string{"testdata/ctx/ctx.go"}); err != nil {
+		t.Fatal(err)
+	}
+	expect := [][3]string{
+		{"example.com/ctx.Hello", "Hello", ""},
+		{"example.com/ctx.Hello", "%d apple", "%d apples"},
+		{"menu", "File", ""},
+		{"menu", "%d file", "%d files"},
+	}
+	f := x.File()
+	if len(f.Messages) != len(expect) {
+		t.Fatalf("expect = %q, got = %v", expect, f.Messages)
+	}
+	for i, v := range f.Messages {
+		if got := [3]string{v.MsgContext, v.MsgId, v.MsgIdPlural}; got != expect[i] {
+			t.Fatalf("%d: expect = %q, got = %q", i, expect[i], got)
+		}
+	}
+}
+
+func TestExtractor_template(t *testing.T) {
+	x := NewExtractor()
+	if err := x.ExtractTemplateFiles([]string{"testdata/templates/hello.html"}); err != nil {
+		t.Fatal(err)
+	}
+	expect := []struct {
+		msgctxt, msgid, msgidPlural string
+		line                        int
+		comment                     string
+	}{
+		{"title", "Hello", "", 1, ""},
+		{"menu", "File", "", 4, "TRANSLATORS: the menu of files."},
+		{"hello.html", "%d file", "%d files", 6, ""},
+		{"hello.html", "Welcome!", "", 8, ""},
+		{"menu", "%d day", "%d days", 10, ""},
+		{"hello.html", "Hello", "", 11, ""},
+	}
+	f := x.File()
+	if len(f.Messages) != len(expect) {
+		t.Fatalf("expect = %d messages, got = %v", len(expect), f.Messages)
+	}
+	for i, v := range f.Messages {
+		e := expect[i]
+		if v.MsgContext != e.msgctxt || v.MsgId != e.msgid || v.MsgIdPlural != e.msgidPlural ||
+			v.ExtractedComment != e.comment ||
+			!reflect.DeepEqual(v.ReferenceFile, []string{"testdata/templates/hello.html"}) ||
+			!reflect.DeepEqual(v.ReferenceLine, []int{e.line}) {
+			t.Fatalf("%d: expect = %v, got = %#v", i, e, v)
+		}
+	}
+
+	var warnings []string
+	for _, w := range x.Warnings() {
+		warnings = append(warnings, w.String())
+	}
+	if expect := []string{"testdata/templates/hello.html:9:16: msgid argument is not a constant string"}; !reflect.DeepEqual(warnings, expect) {
+		t.Fatalf("expect = %q, got = %q", expect, warnings)
+	}
+
+	if err := x.ExtractTemplate("bad.html", "{{gettext . "); err == nil {
+		t.Fatalf("expect error")
+	}
+}
+
+// testModule writes a Go module of the files to a temp dir, and changes
+// the working dir to it.
+func testModule(t *testing.T, files map[string]string) string {
+	dir := t.TempDir()
+	for name, data := range files {
+		name = filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(name), 0777); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(name, []byte(data), 0666); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Chdir(dir)
+	return dir
+}
+
+const testModuleLib = "package lib\n\nimport \"github.com/faxal/gettext-go/gettext\"\n\nfunc Hello() string {\n\treturn gettext.Gettext(\"Hello\")\n}\n"
+
+func TestImportPackage_module(t *testing.T) {
+	dir := testModule(t, map[string]string{
+		"go.mod":     "module example.com/app // the app\n\ngo 1.21\n",
+		"lib/lib.go": testModuleLib,
+	})
+
+	for _, path := range []string{"./lib", filepath.Join(dir, "lib")} {
+		bp, err := ImportPackage(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if bp.ImportPath != "example.com/app/lib" {
+			t.Fatalf("%s: expect = %q, got = %q", path, "example.com/app/lib", bp.ImportPath)
+		}
+	}
+	x := NewExtractor()
+	if err := x.ExtractPackage("./lib"); err != nil {
+		t.Fatal(err)
+	}
+	if f := x.File(); len(f.Messages) != 1 || f.Messages[0].MsgContext != "example.com/app/lib.Hello" {
+		t.Fatalf("bad messages: %v", f.Messages)
+	}
+}
+
+func TestExpandPackages_module(t *testing.T) {
+	testModule(t, map[string]string{
+		"go.mod":                "module example.com/app\n",
+		"main.go":               "package main\n\nfunc main() {}\n",
+		"lib/lib.go":            testModuleLib,
+		"lib/testdata/x.go":     "package x\n",
+		"tools/go.mod":          "module example.com/tools\n",
+		"tools/tools.go":        "package tools\n",
+		"internal/nogo/doc.txt": "no Go files",
+	})
+	paths, err := ExpandPackages([]string{"./..."})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expect := []string{".", "./lib"}; !reflect.DeepEqual(paths, expect) {
+		t.Fatalf("expect = %q, got = %q", expect, paths)
+	}
+	x := NewExtractor()
+	for _, path := range paths {
+		if err := x.ExtractPackage(path); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if f := x.File(); len(f.Messages) != 1 || f.Messages[0].MsgContext != "example.com/app/lib.Hello" {
+		t.Fatalf("bad messages: %v", f.Messages)
+	}
+}
+
+func TestExpandTemplates(t *testing.T) {
+	names, err := ExpandTemplates([]string{"testdata/templates/*.html"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expect := []string{filepath.Join("testdata", "templates", "hello.html")}; !reflect.DeepEqual(names, expect) {
+		t.Fatalf("expect = %q, got = %q", expect, names)
+	}
+	for _, pattern := range []string{"testdata/templates/*.tmpl", "testdata/[templates"} {
+		if _, err := ExpandTemplates([]string{pattern}); err == nil {
+			t.Fatalf("%q: expect an error", pattern)
+		}
+	}
+	var p TemplatePatterns
+	if err := p.Set("[templates"); err == nil {
+		t.Fatal("expect an error of the bad pattern")
+	}
+}
+
+func TestExtractor_examples(t *testing.T) {
+	x := NewExtractor()
+	for _, path := range []string{
+		"github.com/faxal/gettext-go/examples",
+		"github.com/faxal/gettext-go/examples/hi",
+	} {
+		if err := x.ExtractPackage(path); err != nil {
+			t.Fatal(err)
+		}
+	}
+	expect := map[string]string{
+		"main.init": "Gettext in init.",
+		"main.main": "Hello, world!",
+		"main.func": "Gettext in func.",
+		"github.com/faxal/gettext-go/examples/hi.SayHi": "pkg hi: Hello, world!",
+	}
+	f := x.File()
+	if len(f.Messages) != len(expect) {
+		t.Fatalf("expect = %v, got = %v", expect, f.Messages)
+	}
+	for _, v := range f.Messages {
+		if expect[v.MsgContext] != v.MsgId || len(v.ReferenceLine)%2 != 0 {
+			t.Fatalf("bad message: %v", v)
+		}
+	}
+}
+
+func TestPkgSymbolPrefix(t *testing.T) {
+	for _, v := range []struct{ path, prefix string }{
+		{"main", "main"},
+		{"github.com/faxal/gettext-go/gettext", "github.com/faxal/gettext-go/gettext"},
+		{"gopkg.in/yaml.v2", "gopkg.in/yaml%2ev2"},
+		{"example.com/a b", "example.com/a%20b"},
+	} {
+		if s := pkgSymbolPrefix(v.path); s != v.prefix {
+			t.Fatalf("%s: expect = %s, got = %s", v.path, v.prefix, s)
+		}
+	}
+}
+
+func testRef(line int) po.Comment {
+	return po.Comment{
+		ReferenceFile: []string{"example.com/hello/hello.go"},
+		ReferenceLine: []int{line},
+	}
+}
+
+var testHelloMessages = []po.Message{
+	{
+		Comment:    testRef(15),
+		MsgContext: "example.com/hello.init",
+		MsgId:      "Hello, world!",
+	},
+	{
+		Comment:    testRef(18),
+		MsgContext: "example.com/hello.func",
+		MsgId:      "Hello from closure!",
+	},
+	{
+		Comment:    testRef(22),
+		MsgContext: "example.com/hello.init",
+		MsgId:      "Hello from init!",
+	},
+	{
+		Comment: po.Comment{
+			ReferenceFile: []string{"example.com/hello/hello.go"},
+			ReferenceLine: []int{30},
+			Flags:         []string{"go-format"},
+		},
+		MsgContext:  "example.com/hello.(*Greeter).Greet",
+		MsgId:       "%d greeting",
+		MsgIdPlural: "%d greetings",
+	},
+	{
+		Comment:    testRef(34),
+		MsgContext: "farewell",
+		MsgId:      "Goodbye!",
+	},
+	{
+		Comment: po.Comment{
+			ReferenceFile: []string{"example.com/hello/hello.go"},
+			ReferenceLine: []int{34},
+			Flags:         []string{"go-format"},
+		},
+		MsgContext:  "farewell",
+		MsgId:       "%d day",
+		MsgIdPlural: "%d days",
+	},
+	{
+		Comment:    testRef(40),
+		MsgContext: "example.com/hello.func",
+		MsgId:      "Hello from nested closure!",
+	},
+	{
+		Comment: po.Comment{
+			ReferenceFile: []string{"example.com/hello/hello.go", "example.com/hello/hello.go"},
+			ReferenceLine: []int{44, 45},
+		},
+		MsgContext: "example.com/hello.Hello",
+		MsgId:      "Hello from init!",
+	},
+	{
+		Comment:    testRef(49),
+		MsgContext: "example.com/hello.Generic[...]",
+		MsgId:      "Hello from generic!",
+	},
+	{
+		Comment: po.Comment{
+			ExtractedComment: "TRANSLATORS: the File menu.\nKeep it short.",
+			ReferenceFile:    []string{"example.com/hello/hello.go", "example.com/hello/hello.go"},
+			ReferenceLine:    []int{60, 76},
+		},
+		MsgContext: "menu",
+		MsgId:      "File",
+	},
+	{
+		Comment:    testRef(63),
+		MsgContext: "menu",
+		MsgId:      "Edit",
+	},
+	{
+		Comment: po.Comment{
+			ExtractedComment: "TRANSLATORS: the progress.",
+			ReferenceFile:    []string{"example.com/hello/hello.go"},
+			ReferenceLine:    []int{67},
+			Flags:            []string{"go-format"},
+		},
+		MsgContext: "example.com/hello.Menu",
+		MsgId:      "%d%% done",
+	},
+	{
+		Comment: po.Comment{
+			ExtractedComment: "TRANSLATORS: 100% of the files.",
+			ReferenceFile:    []string{"example.com/hello/hello.go"},
+			ReferenceLine:    []int{73},
+		},
+		MsgContext: "example.com/hello.Menu",
+		MsgId:      "100%",
+	},
+}
@@ -0,0 +1,24 @@
+// Copyright 2013 ChaiShushan <chaishushan{AT}gmail.com>. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xgettext
+
+import (
+	"github.com/faxal/gettext-go/gettext/po"
+)
+
+// formatFlags returns the flags of the message, with the "go-format"
+// flag if the msgid or msgid_plural is a format string.
+func formatFlags(msg *po.Message) []string {
+	var flags []string
+	for _, s := range msg.Flags {
+		if s != "go-format" {
+			flags = append(flags, s)
+		}
+	}
+	if po.IsGoFormat(msg.MsgId) || po.IsGoFormat(msg.MsgIdPlural) {
+		flags = append(flags, "go-format")
+	}
+	return flags
+}
@@ -0,0 +1,88 @@
+// Copyright 2013 ChaiShushan <chaishushan{AT}gmail.com>. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xgettext
+
+import (
+	"go/ast"
+	"strings"
+)
+
+// callerName returns the msgctxt of the call, the path is the stack of
+// the nodes from the file to the call. It returns the same name as the
+// callerName of the gettext package at runtime.
+func callerName(pkgName string, path []ast.Node) string {
+	var decl *ast.FuncDecl
+	for _, n := range path {
+		switch n := n.(type) {
+		case *ast.FuncLit:
+			return pkgName + ".func"
+		case *ast.FuncDecl:
+			decl = n
+		}
+	}
+	if decl == nil {
+		return pkgName + ".init" // the package variables
+	}
+	if decl.Recv == nil || len(decl.Recv.List) == 0 {
+		if decl.Name.Name == "init" {
+			return pkgName + ".init"
+		}
+		if decl.Type.TypeParams != nil {
+			return pkgName + "." + decl.Name.Name + "[...]"
+		}
+		return pkgName + "." + decl.Name.Name
+	}
+
+	recv, star := decl.Recv.List[0].Type, false
+	for {
+		switch t := recv.(type) {
+		case *ast.ParenExpr:
+			recv = t.X
+			continue
+		case *ast.StarExpr:
+			recv, star = t.X, true
+			continue
+		}
+		break
+	}
+	var typeName string
+	switch t := recv.(type) {
+	case *ast.Ident:
+		typeName = t.Name
+	case *ast.IndexExpr:
+		typeName = exprName(t.X) + "[...]"
+	case *ast.IndexListExpr:
+		typeName = exprName(t.X) + "[...]"
+	}
+	if star {
+		return pkgName + ".(*" + typeName + ")." + decl.Name.Name
+	}
+	return pkgName + "." + typeName + "." + decl.Name.Name
+}
+
+func exprName(x ast.Expr) string {
+	if id, ok := x.(*ast.Ident); ok {
+		return id.Name
+	}
+	return ""
+}
+
+// pkgSymbolPrefix returns the package path used in the symbol names,
+// the dots of the last element and the special chars are escaped.
+//
+// See Go's source code: cmd/internal/objabi/path.go, PathToPrefix
+func pkgSymbolPrefix(path string) string {
+	const hex = "0123456789abcdef"
+	slash := strings.LastIndex(path, "/")
+	var buf []byte
+	for i := 0; i < len(path); i++ {
+		if c := path[i]; c <= ' ' || (c == '.' && i > slash) || c == '%' || c == '"' || c >= 0x7f {
+			buf = append(buf, '%', hex[c>>4], hex[c&0xf])
+		} else {
+			buf = append(buf, c)
+		}
+	}
+	return string(buf)
+}
@@ -0,0 +1,121 @@
+// Copyright 2013 ChaiShushan <chaishushan{AT}gmail.com>. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xgettext
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const gettextPkgPath = "github.com/faxal/gettext-go/gettext"
+
+// Keyword describes a function which translates its string arguments.
+//
+// The argument positions start at 1, like the xgettext --keyword option.
+type Keyword struct {
+	Name          string // full name like "(*bytes.Buffer).WriteString", or short name like "T" and "i18n.T"
+	MsgContext    int    // position of msgctxt, 0 means none
+	MsgId         int    // position of msgid
+	MsgIdPlural   int    // position of msgid_plural, 0 means none
+	CallerContext bool   // the msgctxt is the caller's function name, like gettext.Gettext
+}
+
+// DefaultKeywords are the translate functions of the gettext package,
+// the methods of gettext.Translator, and the Ctx functions which take
+// a context.Context as the first argument.
+var DefaultKeywords = func() []Keyword {
+	var keywords []Keyword
+	for _, prefix := range []string{
+		gettextPkgPath + ".",
+		"(*" + gettextPkgPath + ".Translator).",
+	} {
+		keywords = append(keywords,
+			Keyword{Name: prefix + "Gettext", MsgId: 1, CallerContext: true},
+			Keyword{Name: prefix + "NGettext", MsgId: 1, MsgIdPlural: 2, CallerContext: true},
+			Keyword{Name: prefix + "PGettext", MsgContext: 1, MsgId: 2},
+			Keyword{Name: prefix + "PNGettext", MsgContext: 1, MsgId: 2, MsgIdPlural: 3},
+			Keyword{Name: prefix + "DGettext", MsgId: 2, CallerContext: true},
+			Keyword{Name: prefix + "DNGettext", MsgId: 2, MsgIdPlural: 3, CallerContext: true},
+			Keyword{Name: prefix + "DPGettext", MsgContext: 2, MsgId: 3},
+			Keyword{Name: prefix + "DPNGettext", MsgContext: 2, MsgId: 3, MsgIdPlural: 4},
+		)
+	}
+	prefix := gettextPkgPath + ".Ctx"
+	keywords = append(keywords,
+		Keyword{Name: prefix + "Gettext", MsgId: 2, CallerContext: true},
+		Keyword{Name: prefix + "NGettext", MsgId: 2, MsgIdPlural: 3, CallerContext: true},
+		Keyword{Name: prefix + "PGettext", MsgContext: 2, MsgId: 3},
+		Keyword{Name: prefix + "PNGettext", MsgContext: 2, MsgId: 3, MsgIdPlural: 4},
+		Keyword{Name: prefix + "DGettext", MsgId: 3, CallerContext: true},
+		Keyword{Name: prefix + "DNGettext", MsgId: 3, MsgIdPlural: 4, CallerContext: true},
+		Keyword{Name: prefix + "DPGettext", MsgContext: 3, MsgId: 4},
+		Keyword{Name: prefix + "DPNGettext", MsgContext: 3, MsgId: 4, MsgIdPlural: 5},
+	)
+	return keywords
+}()
+
+// ParseKeyword parses the keyword spec of the xgettext --keyword option.
+//
+// The spec is "name" or "name:args", the args are separated by comma,
+// "Nc" is the position of msgctxt, the first "N" is the position of msgid
+// and the second is the position of msgid_plural. The msgid is the first
+// argument if there is no args.
+//
+// Examples:
+//	T            // T(msgid)
+//	T:1c,2       // T(msgctxt, msgid)
+//	i18n.N:2,3   // i18n.N(n, msgid, msgidPlural)
+func ParseKeyword(spec string) (Keyword, error) {
+	name, args := spec, ""
+	if i := strings.LastIndex(spec, ":"); i >= 0 {
+		name, args = spec[:i], spec[i+1:]
+	}
+	if name == "" {
+		return Keyword{}, fmt.Errorf("gettext: invalid keyword %q", spec)
+	}
+	k := Keyword{Name: name}
+	if args == "" {
+		k.MsgId = 1
+		return k, nil
+	}
+	for _, arg := range strings.Split(args, ",") {
+		isContext := strings.HasSuffix(arg, "c")
+		n, err := strconv.Atoi(strings.TrimSuffix(arg, "c"))
+		if err != nil || n <= 0 {
+			return Keyword{}, fmt.Errorf("gettext: invalid keyword %q", spec)
+		}
+		switch {
+		case isContext && k.MsgContext == 0:
+			k.MsgContext = n
+		case !isContext && k.MsgId == 0:
+			k.MsgId = n
+		case !isContext && k.MsgIdPlural == 0:
+			k.MsgIdPlural = n
+		default:
+			return Keyword{}, fmt.Errorf("gettext: invalid keyword %q", spec)
+		}
+	}
+	if k.MsgId == 0 {
+		return Keyword{}, fmt.Errorf("gettext: invalid keyword %q, missing msgid", spec)
+	}
+	return k, nil
+}
+
+// match reports whether the keyword is the function of the full name.
+func (k *Keyword) match(fullName string) bool {
+	if k.Name == fullName {
+		return true
+	}
+	// short name, like "T" for "example.com/i18n.T",
+	// and "i18n.T" for "example.com/i18n.T"
+	if strings.Contains(k.Name, "(") {
+		return false
+	}
+	if strings.HasSuffix(fullName, "/"+k.Name) {
+		return true
+	}
+	return !strings.Contains(k.Name, ".") && strings.HasSuffix(fullName, "."+k.Name)
+}
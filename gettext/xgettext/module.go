@@ -0,0 +1,98 @@
+// Copyright 2013 ChaiShushan <chaishushan{AT}gmail.com>. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xgettext
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"go/build"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// ImportPackage finds the Go package of the import path, or the dir like
+// "./cmd/hello".
+//
+// The import path of a dir is resolved by the enclosing go.mod file, so
+// it works in both module mode and GOPATH mode:
+//	./lib -> example.com/app/lib // module example.com/app
+func ImportPackage(path string) (*build.Package, error) {
+	var (
+		bp  *build.Package
+		err error
+	)
+	if filepath.IsAbs(path) || build.IsLocalImport(path) {
+		var dir string
+		if dir, err = filepath.Abs(path); err == nil {
+			bp, err = build.ImportDir(dir, 0)
+		}
+	} else {
+		var wd string
+		if wd, err = os.Getwd(); err == nil {
+			bp, err = build.Import(path, wd, 0)
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("gettext: %v", err)
+	}
+	if bp.ImportPath == "." || build.IsLocalImport(bp.ImportPath) {
+		if importPath, ok := moduleImportPath(bp.Dir); ok {
+			bp.ImportPath = importPath
+		}
+	}
+	if (bp.ImportPath == "." || build.IsLocalImport(bp.ImportPath)) && bp.Name != "main" {
+		// the msgctxt of callerName contains the import path
+		return nil, fmt.Errorf("gettext: can't find the import path of %s", path)
+	}
+	return bp, nil
+}
+
+// moduleImportPath returns the import path of the dir, by the module
+// path of the enclosing go.mod file.
+func moduleImportPath(dir string) (string, bool) {
+	for root := dir; ; {
+		if data, err := ioutil.ReadFile(filepath.Join(root, "go.mod")); err == nil {
+			modPath := modulePath(data)
+			if modPath == "" {
+				return "", false
+			}
+			rel, err := filepath.Rel(root, dir)
+			if err != nil {
+				return "", false
+			}
+			return path.Join(modPath, filepath.ToSlash(rel)), true
+		}
+		parent := filepath.Dir(root)
+		if parent == root {
+			return "", false
+		}
+		root = parent
+	}
+}
+
+// modulePath returns the module path of the go.mod file, or "".
+func modulePath(data []byte) string {
+	s := bufio.NewScanner(bytes.NewReader(data))
+	for s.Scan() {
+		line := strings.TrimSpace(s.Text())
+		if i := strings.Index(line, "//"); i >= 0 {
+			line = strings.TrimSpace(line[:i])
+		}
+		if !strings.HasPrefix(line, "module") {
+			continue
+		}
+		line = strings.TrimSpace(line[len("module"):])
+		if unquoted, err := strconv.Unquote(line); err == nil {
+			line = unquoted
+		}
+		return line
+	}
+	return ""
+}
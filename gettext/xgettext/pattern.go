@@ -0,0 +1,95 @@
+// Copyright 2013 ChaiShushan <chaishushan{AT}gmail.com>. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xgettext
+
+import (
+	"fmt"
+	"go/build"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ExpandPackages expands the "dir/..." patterns of the packages to the
+// package dirs, the other import paths and dirs are returned as is.
+//
+// The dirs named "testdata", or start with "." or "_", and the dirs of
+// the nested modules are skipped, like the go command.
+func ExpandPackages(patterns []string) ([]string, error) {
+	var paths []string
+	for _, pattern := range patterns {
+		if !strings.HasSuffix(pattern, "/...") {
+			paths = append(paths, pattern)
+			continue
+		}
+		root := strings.TrimSuffix(pattern, "/...")
+		if !build.IsLocalImport(root) && !filepath.IsAbs(root) {
+			bp, err := build.Import(root, ".", build.FindOnly)
+			if err != nil {
+				return nil, fmt.Errorf("gettext: %v", err)
+			}
+			root = bp.Dir
+		}
+		err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			if !info.IsDir() {
+				return nil
+			}
+			if name := info.Name(); path != root && (name == "testdata" || name[0] == '.' || name[0] == '_') {
+				return filepath.SkipDir
+			}
+			if _, err := os.Stat(filepath.Join(path, "go.mod")); err == nil && path != root {
+				return filepath.SkipDir // the nested module
+			}
+			if _, err := build.ImportDir(path, 0); err != nil {
+				return nil // no buildable Go files
+			}
+			if !filepath.IsAbs(path) && !build.IsLocalImport(path) {
+				path = "." + string(filepath.Separator) + path
+			}
+			paths = append(paths, path)
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("gettext: %v", err)
+		}
+	}
+	return paths, nil
+}
+
+// TemplatePatterns is the glob patterns of the template files, it's a
+// flag.Value which can be repeated, like the -t flag of xgettext-go.
+type TemplatePatterns []string
+
+func (p *TemplatePatterns) String() string {
+	return fmt.Sprint(*p)
+}
+
+func (p *TemplatePatterns) Set(pattern string) error {
+	if _, err := filepath.Match(pattern, ""); err != nil {
+		return fmt.Errorf("gettext: bad pattern %q: %v", pattern, err)
+	}
+	*p = append(*p, pattern)
+	return nil
+}
+
+// ExpandTemplates expands the glob patterns of the template files, like
+// "templates/*.html". A pattern which matches no file is an error.
+func ExpandTemplates(patterns []string) ([]string, error) {
+	var names []string
+	for _, pattern := range patterns {
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("gettext: bad pattern %q: %v", pattern, err)
+		}
+		if len(matches) == 0 {
+			return nil, fmt.Errorf("gettext: no template files match %q", pattern)
+		}
+		names = append(names, matches...)
+	}
+	return names, nil
+}
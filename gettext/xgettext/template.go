@@ -0,0 +1,252 @@
+// Copyright 2013 ChaiShushan <chaishushan{AT}gmail.com>. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package xgettext
+
+import (
+	"fmt"
+	"go/token"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/template/parse"
+
+	"github.com/faxal/gettext-go/gettext/po"
+)
+
+// DefaultTemplateKeywords are the translate functions of the tmpli18n
+// package, the first argument is the template data. The CallerContext
+// of templates is the template name.
+var DefaultTemplateKeywords = []Keyword{
+	{Name: "gettext", MsgId: 2, CallerContext: true},
+	{Name: "pgettext", MsgContext: 2, MsgId: 3},
+	{Name: "ngettext", MsgId: 2, MsgIdPlural: 3, CallerContext: true},
+	{Name: "pngettext", MsgContext: 2, MsgId: 3, MsgIdPlural: 4},
+	{Name: "dpngettext", MsgContext: 3, MsgId: 4, MsgIdPlural: 5},
+}
+
+// ExtractTemplateFiles extracts the strings of the text/template and
+// html/template files.
+//
+// The template name is the base name of the file, like ParseFiles, or
+// the name of the {{define}} block. The comments just before the action,
+// like {{/* TRANSLATORS: ... */}}, are extracted for translators.
+func (p *Extractor) ExtractTemplateFiles(filenames []string) error {
+	for _, name := range filenames {
+		data, err := ioutil.ReadFile(name)
+		if err != nil {
+			return fmt.Errorf("gettext: %v", err)
+		}
+		if err := p.ExtractTemplate(name, string(data)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ExtractTemplate extracts the strings of the template text, the filename
+// is used as the reference of the messages.
+func (p *Extractor) ExtractTemplate(filename, text string) error {
+	trees := make(map[string]*parse.Tree)
+	t := parse.New(filepath.Base(filename))
+	t.Mode = parse.ParseComments | parse.SkipFuncCheck
+	if _, err := t.Parse(text, "", "", trees); err != nil {
+		return fmt.Errorf("gettext: %v", err)
+	}
+	x := &templateExtractor{
+		Extractor: p,
+		file:      filepath.ToSlash(filename),
+		text:      text,
+	}
+	for _, tree := range trees {
+		x.name = tree.Name
+		x.walk(tree.Root)
+	}
+	// the messages are added in the order of the source
+	sort.SliceStable(x.messages, func(i, j int) bool {
+		return x.messages[i].pos < x.messages[j].pos
+	})
+	for _, v := range x.messages {
+		p.addMessage(v.msg, x.file, x.position(v.pos).Line, v.comment)
+	}
+	return nil
+}
+
+type templateExtractor struct {
+	*Extractor
+	file     string // reference file
+	text     string // source text
+	name     string // name of the current template
+	comment  string // comment for translators of the next action
+	messages []templateMessage
+}
+
+type templateMessage struct {
+	pos     parse.Pos
+	msg     po.Message
+	comment string
+}
+
+func (p *templateExtractor) walk(node parse.Node) {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+		for _, v := range n.Nodes {
+			switch v := v.(type) {
+			case *parse.CommentNode:
+				p.comment = p.commentText(v.Text)
+			case *parse.TextNode:
+				// the comment is for the next action
+			default:
+				p.walk(v)
+				p.comment = ""
+			}
+		}
+	case *parse.ActionNode:
+		p.walk(n.Pipe)
+	case *parse.IfNode:
+		p.walkBranch(&n.BranchNode)
+	case *parse.RangeNode:
+		p.walkBranch(&n.BranchNode)
+	case *parse.WithNode:
+		p.walkBranch(&n.BranchNode)
+	case *parse.TemplateNode:
+		p.walk(n.Pipe)
+	case *parse.PipeNode:
+		if n == nil {
+			return
+		}
+		for i, cmd := range n.Cmds {
+			p.walk(cmd)
+			if k := p.templateKeyword(cmd); k != nil {
+				var prev *parse.CommandNode
+				if i > 0 {
+					prev = n.Cmds[i-1]
+				}
+				p.extractCommand(cmd, prev, k)
+			}
+		}
+	case *parse.ChainNode:
+		p.walk(n.Node)
+	case *parse.CommandNode:
+		for _, v := range n.Args {
+			p.walk(v)
+		}
+	}
+}
+
+func (p *templateExtractor) walkBranch(n *parse.BranchNode) {
+	p.walk(n.Pipe)
+	p.walk(n.List)
+	p.walk(n.ElseList)
+}
+
+// commentText returns the comment for translators of the {{/* */}} text.
+func (p *templateExtractor) commentText(text string) string {
+	if p.CommentTag == "" {
+		return ""
+	}
+	text = strings.TrimSpace(text)
+	if strings.HasPrefix(text, "/*") && strings.HasSuffix(text, "*/") {
+		text = strings.TrimSpace(text[2 : len(text)-2])
+	} else if strings.HasPrefix(text, "//") {
+		text = strings.TrimSpace(text[2:])
+	}
+	if !strings.HasPrefix(text, p.CommentTag) {
+		return ""
+	}
+	return text
+}
+
+// templateKeyword returns the keyword of the called function, or nil.
+func (p *templateExtractor) templateKeyword(cmd *parse.CommandNode) *Keyword {
+	if len(cmd.Args) == 0 {
+		return nil
+	}
+	ident, ok := cmd.Args[0].(*parse.IdentifierNode)
+	if !ok {
+		return nil
+	}
+	keywords := p.TemplateKeywords
+	if keywords == nil {
+		keywords = DefaultTemplateKeywords
+	}
+	for i := 0; i < len(keywords); i++ {
+		if keywords[i].Name == ident.Ident {
+			return &keywords[i]
+		}
+	}
+	return nil
+}
+
+// extractCommand extracts the message of the translate command, prev is
+// the previous command of the pipeline, whose result is the last argument.
+func (p *templateExtractor) extractCommand(cmd, prev *parse.CommandNode, k *Keyword) {
+	var msg po.Message
+	var ok bool
+	if msg.MsgId, ok = p.stringArg(cmd, prev, k.MsgId, "msgid"); !ok {
+		return
+	}
+	if k.MsgIdPlural > 0 {
+		if msg.MsgIdPlural, ok = p.stringArg(cmd, prev, k.MsgIdPlural, "msgid_plural"); !ok {
+			return
+		}
+	}
+	if k.MsgContext > 0 {
+		if msg.MsgContext, ok = p.stringArg(cmd, prev, k.MsgContext, "msgctxt"); !ok {
+			return
+		}
+	} else if k.CallerContext {
+		msg.MsgContext = p.name
+	}
+	p.messages = append(p.messages, templateMessage{cmd.Position(), msg, p.comment})
+}
+
+// stringArg returns the string of the command's argument at pos.
+func (p *templateExtractor) stringArg(cmd, prev *parse.CommandNode, pos int, name string) (string, bool) {
+	var arg parse.Node
+	switch {
+	case pos < len(cmd.Args):
+		arg = cmd.Args[pos]
+	case pos == len(cmd.Args) && prev != nil:
+		if len(prev.Args) == 1 {
+			arg = prev.Args[0]
+		} else {
+			arg = prev
+		}
+	default:
+		p.templateWarnf(cmd.Position(), "missing %s argument %d", name, pos)
+		return "", false
+	}
+	if s, ok := arg.(*parse.StringNode); ok {
+		return s.Text, true
+	}
+	p.templateWarnf(arg.Position(), "%s argument is not a constant string", name)
+	return "", false
+}
+
+// position returns the position of the offset in the source text.
+func (p *templateExtractor) position(pos parse.Pos) token.Position {
+	offset := int(pos)
+	if offset > len(p.text) {
+		offset = len(p.text)
+	}
+	line := 1 + strings.Count(p.text[:offset], "\n")
+	return token.Position{
+		Filename: p.file,
+		Offset:   offset,
+		Line:     line,
+		Column:   offset - strings.LastIndex(p.text[:offset], "\n"),
+	}
+}
+
+func (p *templateExtractor) templateWarnf(pos parse.Pos, format string, args ...interface{}) {
+	p.warnings = append(p.warnings, &Warning{
+		Pos: p.position(pos),
+		Msg: fmt.Sprintf(format, args...),
+	})
+}
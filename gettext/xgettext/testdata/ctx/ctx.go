@@ -0,0 +1,20 @@
+// Copyright 2013 ChaiShushan <chaishushan{AT}gmail.com>. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package ctx
+
+import (
+	"context"
+
+	gt "github.com/faxal/gettext-go/gettext"
+)
+
+func Hello(ctx context.Context, n int) []string {
+	return []string{
+		gt.CtxGettext(ctx, "Hello"),
+		gt.CtxNGettext(ctx, "%d apple", "%d apples", n),
+		gt.CtxPGettext(ctx, "menu", "File"),
+		gt.CtxDPNGettext(ctx, "hello", "menu", "%d file", "%d files", n),
+	}
+}
@@ -0,0 +1,78 @@
+// Copyright 2013 ChaiShushan <chaishushan{AT}gmail.com>. All rights reserved.
+// Use of this source code is governed by a BSD-style
+// license that can be found in the LICENSE file.
+
+package hello
+
+import (
+	"fmt"
+
+	gt "github.com/faxal/gettext-go/gettext"
+)
+
+const world = "world"
+
+var greeting = gt.Gettext("Hello, " + world + "!")
+
+var sayHello = func() string {
+	return gt.Gettext("Hello from closure!")
+}
+
+func init() {
+	fmt.Println(gt.Gettext("Hello from init!"))
+}
+
+type Greeter struct {
+	tr *gt.Translator
+}
+
+func (p *Greeter) Greet(n int) string {
+	return p.tr.NGettext("%d greeting", "%d greetings", n)
+}
+
+func (p Greeter) Farewell() string {
+	return gt.PGettext("farewell", "Goodbye!") + gt.DPNGettext("hello", "farewell", "%d day", "%d days", 2)
+}
+
+func Hello(name string) string {
+	func() {
+		func() {
+			gt.DGettext("hello", "Hello from nested closure!")
+		}()
+	}()
+	gt.Gettext(name) // not a constant
+	gt.Gettext("Hello from init!")
+	return gt.Gettext("Hello from init!")
+}
+
+func Generic[T any](v T) string {
+	return gt.Gettext("Hello from generic!")
+}
+
+// T translates the msgid in the msgctxt, like gettext.PGettext.
+func T(msgctxt, msgid string) string {
+	return gt.PGettext(msgctxt, msgid)
+}
+
+func Menu() []string {
+	// TRANSLATORS: the File menu.
+	// Keep it short.
+	file := T("menu", "File")
+
+	// not for translators
+	edit := T("menu", "Edit")
+
+	// TRANSLATORS: the progress.
+	done := fmt.Sprintf(
+		gt.Gettext("%d%% done"), 100)
+	return []string{
+		file,
+		edit,
+		done,
+		// TRANSLATORS: 100% of the files.
+		gt.Gettext("100%"),
+		// TRANSLATORS: the File menu.
+		// Keep it short.
+		T("menu", "File"),
+	}
+}